@@ -2,7 +2,8 @@ package fangs
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
+	"slices"
 
 	"github.com/anchore/go-logger"
 	"github.com/anchore/go-logger/adapter/discard"
@@ -27,11 +28,63 @@ type Config struct {
 	// Finders are used to search for configuration when no files explicitly specified
 	Finders []Finder `yaml:"-" json:"-" mapstructure:"-"`
 
+	// FS is the filesystem configuration files are found and read from, such as an embed.FS with default configuration
+	// combined with the OS filesystem using NewUnionFS (defaults to the OS filesystem)
+	FS fs.FS `yaml:"-" json:"-" mapstructure:"-"`
+
+	// LookupEnv is used to look up environment variables, including HOME and XDG variables used to find configuration
+	// files (defaults to the process environment)
+	LookupEnv func(key string) (string, bool) `yaml:"-" json:"-" mapstructure:"-"`
+
+	// WorkDir is the working directory searched by finders such as FindInCwd and FindInParentDirs, and which relative
+	// configuration file paths are resolved against (defaults to the process working directory)
+	WorkDir string `yaml:"-" json:"-" mapstructure:"-"`
+
 	// ProfileKey is the top-level configuration key to define profiles
 	ProfileKey string `yaml:"-" json:"-" mapstructure:"-"`
 
+	// ProfileExtendsKey is the key within a profile listing other profiles it extends, which are applied before the
+	// profile itself, so a profile only needs to define the values it changes. Disabled when empty
+	ProfileExtendsKey string `yaml:"-" json:"-" mapstructure:"-"`
+
 	// Profiles specific profiles to load
 	Profiles []string `yaml:"-" json:"-" mapstructure:"-"`
+
+	// DefaultProfilesKey is the top-level configuration key listing profiles to use when no Profiles are requested.
+	// Disabled when empty
+	DefaultProfilesKey string `yaml:"-" json:"-" mapstructure:"-"`
+
+	// ProfileActivations activate profiles based on environment variables when no Profiles are requested, applied
+	// after any default profiles; only profiles defined in the configuration files are activated
+	ProfileActivations []ProfileActivation `yaml:"-" json:"-" mapstructure:"-"`
+
+	// IncludeKey is the top-level configuration key listing other configuration files to include, such as a shared base
+	// configuration; relative paths are resolved against the including file and may be glob patterns. Included files
+	// have lower priority than the including file. Includes are disabled when empty
+	IncludeKey string `yaml:"-" json:"-" mapstructure:"-"`
+
+	// SetFlag is the name of a repeatable flag added by AddFlags to set any configuration value with a key.path=value
+	// assignment, such as: --set scanning.depth=5; these have the highest precedence. The flag is not added when empty
+	SetFlag string `yaml:"-" json:"-" mapstructure:"-"`
+
+	// Overrides are key.path=value assignments applied with the highest precedence, normally set using the SetFlag
+	Overrides []string `yaml:"-" json:"-" mapstructure:"-"`
+
+	// MergeStrategies sets the MergeStrategy for lists at configuration paths, such as "scanning.exclude", taking
+	// precedence over merge struct tags
+	MergeStrategies map[string]MergeStrategy `yaml:"-" json:"-" mapstructure:"-"`
+
+	// MergeKeys sets the key of list entries at configuration paths, such as "scanners": "name", so entries with the
+	// same key are merged instead of being added to the list, taking precedence over merge struct tags
+	MergeKeys map[string]string `yaml:"-" json:"-" mapstructure:"-"`
+
+	// Sources are additional sources of configuration values, merged with configuration files, environment variables
+	// and flags based on their priority
+	Sources []Source `yaml:"-" json:"-" mapstructure:"-"`
+
+	// Strict causes loading to fail when configuration files or Sources contain keys that are not used by any
+	// configuration
+	Strict bool `yaml:"-" json:"-" mapstructure:"-"`
 }
 
 var _ FlagAdder = (*Config)(nil)
@@ -54,20 +107,44 @@ func NewConfig(appName string) Config {
 			FindInHomeDir,
 			// 5. look for <appname>/config.<ext> in xdg locations
 			FindInXDG,
+			// 6. look for /etc/<appname>/config.<ext> and /etc/<appname>.<ext>
+			FindInSystemDir,
 		},
 	}
 }
 
 // WithConfigEnvVar looks for the environment variable: <APP_NAME>_CONFIG as a way to specify a config file
-// This will be overridden by a command-line flag
+// This will be overridden by a command-line flag. LookupEnv, if used, must be set before calling this
 func (c Config) WithConfigEnvVar() Config {
-	envConfig := os.Getenv(envVar(c.AppName, "CONFIG"))
+	envConfig, _ := lookupEnv(c, envVar(c.AppName, "CONFIG"))
 	if envConfig != "" {
 		c.Files = Flatten(envConfig)
 	}
 	return c
 }
 
+// WithProfileEnvVar looks for the environment variable: <APP_NAME>_PROFILE as a comma-separated list of profiles to
+// use. This will be overridden by the --profile flag. LookupEnv, if used, must be set before calling this
+func (c Config) WithProfileEnvVar() Config {
+	envProfiles, _ := lookupEnv(c, envVar(c.AppName, "PROFILE"))
+	if envProfiles != "" {
+		c.Profiles = Flatten(envProfiles)
+	}
+	return c
+}
+
+// WithConfD adds FindInConfD to the Finders, to load drop-in configuration fragments from conf.d directories. These
+// have a lower precedence than any other configuration files found except system-wide configuration, so FindInConfD is
+// added before FindInSystemDir, or last if FindInSystemDir is not one of the Finders
+func (c Config) WithConfD() Config {
+	i := indexOfFinder(c.Finders, FindInSystemDir)
+	if i < 0 {
+		i = len(c.Finders)
+	}
+	c.Finders = slices.Insert(slices.Clone(c.Finders), i, FindInConfD)
+	return c
+}
+
 func (c *Config) AddFlags(flags FlagSet) {
 	if c.MultiFile {
 		flags.StringArrayVarP(&c.Files, "config", "c", fmt.Sprintf("%s configuration file(s) to use", c.AppName))
@@ -81,4 +158,7 @@ func (c *Config) AddFlags(flags FlagSet) {
 	if c.ProfileKey != "" {
 		flags.StringArrayVarP(&c.Profiles, "profile", "", "configuration profiles to use")
 	}
+	if c.SetFlag != "" {
+		flags.StringArrayVarP(&c.Overrides, c.SetFlag, "", "set a configuration value, such as: key.path=value")
+	}
 }
@@ -0,0 +1,137 @@
+// Package configcmd provides a ready-made `config` cobra command for applications using fangs, to print the
+// application configuration, the locations searched for configuration files, and to write a starter configuration file
+package configcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anchore/fangs"
+)
+
+const (
+	outputYAML = "yaml"
+	outputJSON = "json"
+)
+
+// New returns a `config` command which prints the configuration values, along with a `locations` subcommand which
+// prints the locations searched for configuration files and an `init` subcommand which writes a starter configuration
+// file. The cfg should be the same Config used to add flags to the root command, so flags such as --config and
+// --profile are honored. Values are the application configuration structs, as would be passed to fangs.Load; these are
+// printed with their defaults unless --load is specified, in which case the configuration is loaded first. The filter
+// is applied to the output, e.g. to redact sensitive values
+func New(cfg *fangs.Config, filter fangs.ValueFilterFunc, values ...any) *cobra.Command {
+	load := false
+	output := outputYAML
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: fmt.Sprintf("show the %s configuration", cfg.AppName),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var prov fangs.Provenance
+			if load {
+				var err error
+				prov, err = fangs.LoadWithProvenance(*cfg, cmd, values...)
+				if err != nil {
+					return err
+				}
+			}
+
+			var out string
+			switch output {
+			case outputYAML:
+				out = fangs.SummarizeCommandWithProvenance(*cfg, cmd, filter, prov, values...)
+			case outputJSON:
+				contents, err := json.MarshalIndent(fangs.ToMap(*cfg, values...), "", "  ")
+				if err != nil {
+					return fmt.Errorf("unable to marshal configuration: %w", err)
+				}
+				out = string(contents) + "\n"
+				if filter != nil {
+					out = filter(out)
+				}
+			default:
+				return fmt.Errorf("unsupported output format: %s, expected one of: %s, %s", output, outputYAML, outputJSON)
+			}
+
+			_, err := io.WriteString(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&load, "load", load, "load the configuration from files, environment variables and flags, rather than showing defaults")
+	flags.StringVar(&output, "output", output, fmt.Sprintf("output format, one of: %s, %s", outputYAML, outputJSON))
+
+	cmd.AddCommand(newLocations(cfg), newInit(cfg, values...))
+
+	return cmd
+}
+
+// newLocations returns a `locations` command which prints the locations searched for configuration files, or the
+// files specified explicitly, indicating whether each exists
+func newLocations(cfg *fangs.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "locations",
+		Short: fmt.Sprintf("show the locations searched for %s configuration files", cfg.AppName),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			for _, location := range locations(*cfg) {
+				status := "missing"
+				if fangs.LocationExists(*cfg, location) {
+					status = "exists"
+				}
+				if _, err := fmt.Fprintf(out, "%-7s %s\n", status, location); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// newInit returns an `init` command which writes a starter configuration file with the default values, to the
+// provided path or the first writable location searched for configuration files
+func newInit(cfg *fangs.Config, values ...any) *cobra.Command {
+	force := false
+
+	cmd := &cobra.Command{
+		Use:   "init [path]",
+		Short: fmt.Sprintf("write a %s configuration file with default values", cfg.AppName),
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			file, err := fangs.WriteConfigFile(*cfg, cmd, path, force, values...)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "wrote configuration file: %s\n", file)
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", force, "overwrite an existing configuration file")
+
+	return cmd
+}
+
+// locations returns the explicitly specified configuration files, if any, otherwise all the locations searched
+func locations(cfg fangs.Config) (out []string) {
+	for _, file := range cfg.Files {
+		if file != "" {
+			out = append(out, file)
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	return fangs.SummarizeLocations(cfg)
+}
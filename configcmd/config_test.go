@@ -0,0 +1,216 @@
+package configcmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/fangs"
+)
+
+type sub struct {
+	Enabled bool   `mapstructure:"enabled" description:"enable the thing"`
+	Token   string `mapstructure:"token"`
+}
+
+type options struct {
+	Name  string `mapstructure:"name"`
+	Depth int    `mapstructure:"depth"`
+	Sub   sub    `mapstructure:"sub"`
+}
+
+func newRoot(t *testing.T, dir string) (*cobra.Command, *options) {
+	t.Helper()
+
+	t.Chdir(dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+
+	cfg := fangs.NewConfig("app")
+	cfg.Finders = []fangs.Finder{fangs.FindInCwd}
+
+	opts := &options{
+		Name:  "default-name",
+		Depth: 1,
+	}
+
+	root := &cobra.Command{
+		Use: "app",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return nil
+		},
+	}
+	cfg.AddFlags(fangs.NewPFlagSet(cfg.Logger, root.PersistentFlags()))
+	root.PersistentFlags().IntVar(&opts.Depth, "depth", opts.Depth, "how deep to go")
+
+	redact := func(s string) string {
+		return strings.ReplaceAll(s, "secret", "*******")
+	}
+	root.AddCommand(New(&cfg, redact, opts))
+
+	return root, opts
+}
+
+func run(t *testing.T, root *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func writeConfig(t *testing.T, dir string) string {
+	t.Helper()
+	file := filepath.Join(dir, ".app.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: file-name\nsub:\n  enabled: true\n  token: secret\n"), 0600))
+	return file
+}
+
+func Test_Config(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir)
+	root, opts := newRoot(t, dir)
+
+	out, err := run(t, root, "config")
+	require.NoError(t, err)
+	require.Equal(t, `# (env: APP_NAME)
+name: 'default-name'
+
+# how deep to go (env: APP_DEPTH)
+depth: 1
+
+sub:
+  # enable the thing (env: APP_SUB_ENABLED)
+  enabled: false
+
+  # (env: APP_SUB_TOKEN)
+  token: ''
+
+`, out)
+	require.Equal(t, "default-name", opts.Name)
+}
+
+func Test_ConfigLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir)
+	root, _ := newRoot(t, dir)
+
+	out, err := run(t, root, "config", "--load", "--depth", "5")
+	require.NoError(t, err)
+	require.Equal(t, `# (env: APP_NAME)
+# from: .app.yaml
+name: 'file-name'
+
+# how deep to go (env: APP_DEPTH)
+# from: flag --depth
+depth: 5
+
+sub:
+  # enable the thing (env: APP_SUB_ENABLED)
+  # from: .app.yaml
+  enabled: true
+
+  # (env: APP_SUB_TOKEN)
+  # from: .app.yaml
+  token: '*******'
+
+`, out)
+}
+
+func Test_ConfigJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir)
+	root, _ := newRoot(t, dir)
+
+	out, err := run(t, root, "config", "--load", "--output", "json")
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "name": "file-name",
+  "depth": 1,
+  "sub": {
+    "enabled": true,
+    "token": "*******"
+  }
+}`, out)
+
+	_, err = run(t, root, "config", "--output", "xml")
+	require.ErrorContains(t, err, "unsupported output format: xml")
+}
+
+func Test_ConfigLocations(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir)
+	root, _ := newRoot(t, dir)
+
+	out, err := run(t, root, "config", "locations")
+	require.NoError(t, err)
+
+	var expected []string
+	for _, location := range fangs.FindInCwd(fangs.NewConfig("app")) {
+		status := "missing"
+		if location == ".app.yaml" {
+			status = "exists"
+		}
+		expected = append(expected, fmt.Sprintf("%-7s %s", status, location))
+	}
+	require.Equal(t, strings.Join(expected, "\n")+"\n", out)
+
+	missing := filepath.Join(dir, "missing.yaml")
+	out, err = run(t, root, "config", "locations", "-c", ".app.yaml", "-c", missing)
+	require.NoError(t, err)
+	require.Equal(t, "exists  .app.yaml\nmissing "+missing+"\n", out)
+}
+
+func Test_ConfigLocationsUsesFSAndLookupEnv(t *testing.T) {
+	env := map[string]string{"HOME": "/sandbox/home"}
+	cfg := fangs.NewConfig("app")
+	cfg.FS = fstest.MapFS{
+		"sandbox/home/.app.yaml": {Data: []byte("name: home-name\n")},
+	}
+	cfg.LookupEnv = func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	cfg.Files = []string{"~/.app.yaml", "~/missing.yaml"}
+
+	root := &cobra.Command{Use: "app"}
+	root.AddCommand(New(&cfg, nil, &options{}))
+
+	out, err := run(t, root, "config", "locations")
+	require.NoError(t, err)
+	require.Equal(t, "exists  ~/.app.yaml\nmissing ~/missing.yaml\n", out)
+}
+
+func Test_ConfigInit(t *testing.T) {
+	dir := t.TempDir()
+	root, _ := newRoot(t, dir)
+
+	out, err := run(t, root, "config", "init")
+	require.NoError(t, err)
+	require.Equal(t, "wrote configuration file: .app.yaml\n", out)
+
+	contents, err := os.ReadFile(filepath.Join(dir, ".app.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "# how deep to go (env: APP_DEPTH)\ndepth: 1\n")
+
+	_, err = run(t, root, "config", "init")
+	require.ErrorContains(t, err, "configuration file already exists: .app.yaml")
+
+	_, err = run(t, root, "config", "init", "--force")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "other", "config.yaml")
+	out, err = run(t, root, "config", "init", path)
+	require.NoError(t, err)
+	require.Equal(t, "wrote configuration file: "+path+"\n", out)
+	require.FileExists(t, path)
+}
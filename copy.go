@@ -0,0 +1,107 @@
+package fangs
+
+import (
+	"reflect"
+)
+
+// addressMap maps the addresses of values in an original configuration to the addresses of the corresponding values
+// in a copy
+type addressMap map[uintptr]uintptr
+
+// deepCopy returns a copy of the value, which must be a pointer, such that no pointers, slices or maps are shared with
+// the original. Unexported fields are copied shallowly. The returned addressMap maps the address of each field in the
+// original to the same field in the copy
+func deepCopy[T any](value T) (T, addressMap) {
+	addresses := addressMap{}
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !isPtr(v.Type()) || v.IsNil() {
+		return value, addresses
+	}
+	out := copyValue(v, addresses)
+	return out.Interface().(T), addresses
+}
+
+func copyValue(v reflect.Value, addresses addressMap) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	if !v.IsValid() || isNil(v) {
+		return out
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		copyInto(elem.Elem(), v.Elem(), addresses)
+		out.Set(elem)
+	case reflect.Slice:
+		out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			copyInto(out.Index(i), v.Index(i), addresses)
+		}
+	case reflect.Map:
+		out.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		i := v.MapRange()
+		for i.Next() {
+			out.SetMapIndex(i.Key(), copyValue(i.Value(), addresses))
+		}
+	default:
+		copyInto(out, v, addresses)
+	}
+	return out
+}
+
+// copyInto copies the value to the settable destination, recording addresses of addressable values
+func copyInto(dst, src reflect.Value, addresses addressMap) {
+	if src.CanAddr() && dst.CanAddr() {
+		addresses[src.Addr().Pointer()] = dst.Addr().Pointer()
+	}
+
+	switch src.Kind() {
+	case reflect.Struct:
+		// shallow copy first, so unexported fields are included
+		dst.Set(src)
+		copyFields(dst, src, addresses)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyInto(dst.Index(i), src.Index(i), addresses)
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		dst.Set(copyValue(src, addresses))
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		dst.Set(copyValue(src.Elem(), addresses))
+	default:
+		dst.Set(src)
+	}
+}
+
+// copyFields deep copies the settable fields of a struct, including exported fields of embedded unexported structs
+func copyFields(dst, src reflect.Value, addresses addressMap) {
+	for i := 0; i < src.NumField(); i++ {
+		switch {
+		case dst.Field(i).CanSet():
+			copyInto(dst.Field(i), src.Field(i), addresses)
+		case src.Field(i).Kind() == reflect.Struct:
+			if src.Field(i).CanAddr() {
+				addresses[src.Field(i).Addr().Pointer()] = dst.Field(i).Addr().Pointer()
+			}
+			copyFields(dst.Field(i), src.Field(i), addresses)
+		}
+	}
+}
+
+// rebaseFlagRefs returns flag references for the copy of a configuration, so flags referencing fields in the original
+// configuration are applied to the corresponding fields in the copy
+func rebaseFlagRefs(flags flagRefs, addresses addressMap) flagRefs {
+	out := flagRefs{}
+	for ref, flag := range flags {
+		if addr, ok := addresses[ref]; ok {
+			out[addr] = flag
+			continue
+		}
+		out[ref] = flag
+	}
+	return out
+}
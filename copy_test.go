@@ -0,0 +1,79 @@
+package fangs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_deepCopy(t *testing.T) {
+	type sub struct {
+		Name  string
+		Items []string
+	}
+	type embedded struct {
+		Value string
+	}
+	type config struct {
+		embedded
+		Sub     sub
+		SubPtr  *sub
+		Subs    []sub
+		Labels  map[string]*sub
+		Any     any
+		private *sub
+	}
+
+	orig := &config{
+		embedded: embedded{Value: "embedded"},
+		Sub:      sub{Name: "sub", Items: []string{"a"}},
+		SubPtr:   &sub{Name: "ptr"},
+		Subs:     []sub{{Name: "subs", Items: []string{"b"}}},
+		Labels:   map[string]*sub{"label": {Name: "label"}},
+		Any:      &sub{Name: "any"},
+		private:  &sub{Name: "private"},
+	}
+
+	cp, addresses := deepCopy(orig)
+	require.Equal(t, orig, cp)
+	require.NotSame(t, orig, cp)
+	require.NotSame(t, orig.SubPtr, cp.SubPtr)
+	require.NotSame(t, orig.Labels["label"], cp.Labels["label"])
+	require.NotSame(t, orig.Any, cp.Any)
+	// unexported fields are copied shallowly
+	require.Same(t, orig.private, cp.private)
+
+	cp.Sub.Items[0] = "changed"
+	cp.Subs[0].Items[0] = "changed"
+	cp.Value = "changed"
+	require.Equal(t, "a", orig.Sub.Items[0])
+	require.Equal(t, "b", orig.Subs[0].Items[0])
+	require.Equal(t, "embedded", orig.Value)
+
+	require.Equal(t, addrOf(&cp.Sub.Name), addresses[addrOf(&orig.Sub.Name)])
+	require.Equal(t, addrOf(&cp.SubPtr.Name), addresses[addrOf(&orig.SubPtr.Name)])
+	require.Equal(t, addrOf(&cp.Value), addresses[addrOf(&orig.Value)])
+}
+
+func Test_rebaseFlagRefs(t *testing.T) {
+	type config struct {
+		Name  string
+		Depth int
+	}
+	orig := &config{Name: "name", Depth: 1}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&orig.Name, "name", orig.Name, "")
+	cmd.Flags().IntVar(&orig.Depth, "depth", orig.Depth, "")
+
+	cp, addresses := deepCopy(orig)
+	refs := rebaseFlagRefs(commandFlagRefs(cmd), addresses)
+	require.Equal(t, "name", refs[addrOf(&cp.Name)].Name)
+	require.Equal(t, "depth", refs[addrOf(&cp.Depth)].Name)
+}
+
+func addrOf[T any](v *T) uintptr {
+	return reflect.ValueOf(v).Pointer()
+}
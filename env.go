@@ -0,0 +1,91 @@
+package fangs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+
+	"github.com/anchore/go-homedir"
+)
+
+// lookupEnv returns the value of the environment variable using Config.LookupEnv, or the process environment if not
+// set
+func lookupEnv(cfg Config, key string) (string, bool) {
+	if cfg.LookupEnv != nil {
+		return cfg.LookupEnv(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// homeDir returns the user's home directory; when Config.LookupEnv is set, this is the HOME environment variable
+// (or USERPROFILE on Windows)
+func homeDir(cfg Config) (string, error) {
+	if cfg.LookupEnv == nil {
+		return homedir.Dir()
+	}
+	for _, key := range []string{"HOME", "USERPROFILE"} {
+		if home, ok := cfg.LookupEnv(key); ok && home != "" {
+			return home, nil
+		}
+	}
+	return "", fmt.Errorf("HOME environment variable is not set")
+}
+
+// workDir returns the working directory: Config.WorkDir, or the process working directory if not set
+func workDir(cfg Config) (string, error) {
+	if cfg.WorkDir != "" {
+		return cfg.WorkDir, nil
+	}
+	return os.Getwd()
+}
+
+// workDirPath returns a relative path within Config.WorkDir, or the path unchanged if it is absolute or WorkDir is
+// not set, in which case relative paths are resolved against the process working directory
+func workDirPath(cfg Config, path string) string {
+	if cfg.WorkDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cfg.WorkDir, path)
+}
+
+// expandHome expands a path beginning with ~ to be within the user's home directory
+func expandHome(cfg Config, path string) (string, error) {
+	if cfg.LookupEnv == nil {
+		return homedir.Expand(path)
+	}
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	if len(path) > 1 && path[1] != '/' && path[1] != '\\' {
+		return "", fmt.Errorf("cannot expand user-specific home dir: %s", path)
+	}
+	home, err := homeDir(cfg)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// xdgConfigDirs returns the xdg config home and config directories; when Config.LookupEnv is set, these are
+// XDG_CONFIG_HOME and XDG_CONFIG_DIRS, or the defaults from the XDG Base Directory Specification if not set
+func xdgConfigDirs(cfg Config) (configHome string, configDirs []string) {
+	if cfg.LookupEnv == nil {
+		return xdg.ConfigHome, xdg.ConfigDirs
+	}
+
+	if dir, ok := cfg.LookupEnv("XDG_CONFIG_HOME"); ok && dir != "" {
+		configHome = dir
+	} else if home, err := homeDir(cfg); err == nil {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	if dirs, ok := cfg.LookupEnv("XDG_CONFIG_DIRS"); ok && dirs != "" {
+		configDirs = filepath.SplitList(dirs)
+	} else {
+		configDirs = []string{"/etc/xdg"}
+	}
+	return configHome, configDirs
+}
@@ -0,0 +1,171 @@
+package fangs
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HermeticLoad(t *testing.T) {
+	type config struct {
+		Name  string   `mapstructure:"name"`
+		Depth int      `mapstructure:"depth"`
+		Label string   `mapstructure:"label"`
+		Items []string `mapstructure:"items"`
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected *config
+	}{
+		{
+			name: "home and default xdg dirs",
+			env: map[string]string{
+				"HOME": "/home/user",
+			},
+			expected: &config{Name: "home", Depth: 1, Label: "xdg-home", Items: []string{"home", "xdg-home", "etc-xdg"}},
+		},
+		{
+			name: "xdg variables",
+			env: map[string]string{
+				"HOME":            "/home/other",
+				"XDG_CONFIG_HOME": "/custom",
+				"XDG_CONFIG_DIRS": "/etc/custom",
+			},
+			expected: &config{Name: "custom", Depth: 2, Items: []string{"custom", "etc-custom"}},
+		},
+		{
+			name: "environment variables and config file variable",
+			env: map[string]string{
+				"HOME":       "/home/user",
+				"APP_CONFIG": "~/other.yaml",
+				"APP_DEPTH":  "5",
+			},
+			expected: &config{Name: "other", Depth: 5},
+		},
+	}
+
+	fsys := fstest.MapFS{
+		"home/user/.app.yaml":               {Data: []byte("name: home\ndepth: 1\nitems: [home]\n")},
+		"home/user/other.yaml":              {Data: []byte("name: other\n")},
+		"home/user/.config/app/config.yaml": {Data: []byte("name: xdg-home\nlabel: xdg-home\nitems: [xdg-home]\n")},
+		"etc/xdg/app/config.yaml":           {Data: []byte("items: [etc-xdg]\n")},
+		"custom/app/config.yaml":            {Data: []byte("name: custom\ndepth: 2\nitems: [custom]\n")},
+		"etc/custom/app/config.yaml":        {Data: []byte("items: [etc-custom]\n")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewConfig("app")
+			cfg.FS = fsys
+			cfg.LookupEnv = func(key string) (string, bool) {
+				value, ok := test.env[key]
+				return value, ok
+			}
+			cfg.Finders = []Finder{FindInHomeDir, FindInXDG}
+			cfg = cfg.WithConfigEnvVar()
+
+			c := &config{}
+			require.NoError(t, Load(cfg, &cobra.Command{}, c))
+			require.Equal(t, test.expected, c)
+		})
+	}
+}
+
+func Test_HermeticWorkDir(t *testing.T) {
+	type config struct {
+		Name string `mapstructure:"name"`
+	}
+
+	tests := []struct {
+		name     string
+		workDir  string
+		files    []string
+		finders  []Finder
+		expected string
+	}{
+		{
+			name:     "cwd",
+			workDir:  "/projects/a",
+			finders:  []Finder{FindInCwd},
+			expected: "a",
+		},
+		{
+			name:     "app name subdir",
+			workDir:  "/projects/b",
+			finders:  []Finder{FindInAppNameSubdir},
+			expected: "b",
+		},
+		{
+			name:     "parent dirs",
+			workDir:  "/projects/b/sub/dir",
+			finders:  []Finder{FindInParentDirs(".git")},
+			expected: "b-sub",
+		},
+		{
+			name:     "relative file",
+			workDir:  "/projects/a",
+			files:    []string{"other.yaml"},
+			finders:  []Finder{FindInCwd},
+			expected: "a-other",
+		},
+	}
+
+	fsys := fstest.MapFS{
+		"projects/a/.app.yaml":          {Data: []byte("name: a\n")},
+		"projects/a/other.yaml":         {Data: []byte("name: a-other\n")},
+		"projects/b/.git/HEAD":          {Data: []byte("ref: refs/heads/main\n")},
+		"projects/b/.app/config.yaml":   {Data: []byte("name: b\n")},
+		"projects/b/sub/.app.yaml":      {Data: []byte("name: b-sub\n")},
+		"projects/.app.yaml":            {Data: []byte("name: projects\n")},
+		"projects/b/sub/dir/.app.other": {Data: []byte("not configuration")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewConfig("app")
+			cfg.FS = fsys
+			cfg.WorkDir = test.workDir
+			cfg.Files = test.files
+			cfg.Finders = test.finders
+
+			c := &config{}
+			require.NoError(t, Load(cfg, &cobra.Command{}, c))
+			require.Equal(t, test.expected, c.Name)
+		})
+	}
+}
+
+func Test_expandHome(t *testing.T) {
+	cfg := NewConfig("app")
+	cfg.LookupEnv = func(key string) (string, bool) {
+		if key == "HOME" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+
+	got, err := expandHome(cfg, "~/.app.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "/home/user/.app.yaml", got)
+
+	got, err = expandHome(cfg, "relative/~/.app.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "relative/~/.app.yaml", got)
+
+	_, err = expandHome(cfg, "~other/.app.yaml")
+	require.Error(t, err)
+
+	cfg.LookupEnv = func(string) (string, bool) {
+		return "", false
+	}
+	_, err = expandHome(cfg, "~/.app.yaml")
+	require.ErrorContains(t, err, "HOME environment variable is not set")
+}
@@ -0,0 +1,47 @@
+package fangstest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+
+	"github.com/anchore/fangs"
+)
+
+var update = flag.Bool("fangstest.update", false, "update fangstest golden files")
+
+// AssertGolden compares the actual value with the contents of the golden file <GoldenDir>/<name>.golden, failing the
+// test if they differ. Golden files are written when running tests with -fangstest.update
+func (s *Sandbox) AssertGolden(name, actual string) {
+	s.t.Helper()
+
+	file := filepath.Join(s.GoldenDir, name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			s.t.Fatalf("unable to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(actual), 0o600); err != nil {
+			s.t.Fatalf("unable to update golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(file)
+	if err != nil {
+		s.t.Fatalf("unable to read golden file, run with -fangstest.update to create it: %v", err)
+	}
+
+	if diff := cmp.Diff(string(expected), actual); diff != "" {
+		s.t.Errorf("%s does not match golden file %s (-expected +actual):\n%s", name, file, diff)
+	}
+}
+
+// AssertSummary compares the fangs.SummarizeCommand output for the values with the golden file, see AssertGolden
+func (s *Sandbox) AssertSummary(name string, cfg fangs.Config, cmd *cobra.Command, values ...any) {
+	s.t.Helper()
+	s.AssertGolden(name, fangs.SummarizeCommand(cfg, cmd, nil, values...))
+}
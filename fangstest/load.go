@@ -0,0 +1,31 @@
+package fangstest
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+
+	"github.com/anchore/fangs"
+)
+
+// Load loads configuration in the sandbox into a copy of the defaults with fangs.LoadInto, using the sandbox Config
+// and the flags already parsed by the command, if any, failing the test if loading fails
+func Load[T any](s *Sandbox, cmd *cobra.Command, defaults *T) *T {
+	s.t.Helper()
+
+	loaded, err := fangs.LoadInto(s.Config(), cmd, defaults)
+	if err != nil {
+		s.t.Fatalf("unable to load configuration: %v", err)
+	}
+	return loaded
+}
+
+// AssertLoad loads configuration in the sandbox the same as Load, failing the test if the loaded configuration is not
+// equal to the expected configuration
+func AssertLoad[T any](s *Sandbox, cmd *cobra.Command, defaults *T, expected *T) {
+	s.t.Helper()
+
+	loaded := Load(s, cmd, defaults)
+	if diff := cmp.Diff(expected, loaded); diff != "" {
+		s.t.Errorf("loaded configuration does not match (-expected +actual):\n%s", diff)
+	}
+}
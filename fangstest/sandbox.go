@@ -0,0 +1,153 @@
+// Package fangstest provides helpers to test applications using fangs: a sandbox with isolated working, home and XDG
+// directories and environment variables, to write configuration files in the locations searched by the Finders,
+// load and assert on configuration, run commands and compare output with golden files
+package fangstest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anchore/fangs"
+)
+
+// Sandbox is an isolated environment to load configuration in, with temporary directories for the working directory,
+// home directory and XDG config directories, and an environment which does not include the process environment.
+// The process working directory and environment are not changed, so sandboxes may be used in parallel tests
+type Sandbox struct {
+	t       testing.TB
+	appName string
+	env     map[string]string
+
+	// Dir is the temporary directory containing all other sandbox directories
+	Dir string
+
+	// WorkDir is the working directory searched by the Finders and relative paths are resolved against, the
+	// fangs.Config WorkDir
+	WorkDir string
+
+	// Home is the home directory, the HOME environment variable
+	Home string
+
+	// XDGConfigHome is the XDG config home directory, the XDG_CONFIG_HOME environment variable
+	XDGConfigHome string
+
+	// XDGConfigDir is the single XDG config directory, the XDG_CONFIG_DIRS environment variable
+	XDGConfigDir string
+
+	// GoldenDir is the directory golden files are read from and written to, testdata in the test's working directory
+	GoldenDir string
+}
+
+// New creates a Sandbox for an application
+func New(t testing.TB, appName string) *Sandbox {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unable to get working directory: %v", err)
+	}
+
+	dir := t.TempDir()
+	s := &Sandbox{
+		t:             t,
+		appName:       appName,
+		Dir:           dir,
+		WorkDir:       filepath.Join(dir, "work"),
+		Home:          filepath.Join(dir, "home"),
+		XDGConfigHome: filepath.Join(dir, "xdg-config-home"),
+		XDGConfigDir:  filepath.Join(dir, "xdg-config-dir"),
+		GoldenDir:     filepath.Join(wd, "testdata"),
+	}
+	for _, d := range []string{s.WorkDir, s.Home, s.XDGConfigHome, s.XDGConfigDir} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			t.Fatalf("unable to create sandbox directory: %v", err)
+		}
+	}
+
+	s.env = map[string]string{
+		"HOME":            s.Home,
+		"XDG_CONFIG_HOME": s.XDGConfigHome,
+		"XDG_CONFIG_DIRS": s.XDGConfigDir,
+	}
+
+	return s
+}
+
+// Config returns a new fangs.Config for the application which uses the sandbox environment. The sandbox has no
+// system-wide configuration directory, so fangs.FindInSystemDir is not one of the Finders and configuration in the
+// host /etc is ignored
+func (s *Sandbox) Config() fangs.Config {
+	cfg := fangs.NewConfig(s.appName)
+	cfg.LookupEnv = s.LookupEnv
+	cfg.WorkDir = s.WorkDir
+	cfg.Finders = slices.DeleteFunc(cfg.Finders, func(f fangs.Finder) bool {
+		return reflect.ValueOf(f).Pointer() == reflect.ValueOf(fangs.FindInSystemDir).Pointer()
+	})
+	return cfg
+}
+
+// Setenv sets an environment variable in the sandbox environment
+func (s *Sandbox) Setenv(key, value string) {
+	s.env[key] = value
+}
+
+// LookupEnv looks up an environment variable in the sandbox environment, suitable for fangs.Config.LookupEnv
+func (s *Sandbox) LookupEnv(key string) (string, bool) {
+	value, ok := s.env[key]
+	return value, ok
+}
+
+// WriteFile writes a file in the sandbox, returning the full path. Relative paths are within the working directory,
+// paths beginning with ~/ are within the home directory. Use the sandbox directories for other locations, such as:
+// filepath.Join(s.XDGConfigHome, "app", "config.yaml")
+func (s *Sandbox) WriteFile(path, contents string) string {
+	s.t.Helper()
+
+	switch {
+	case strings.HasPrefix(path, "~/"):
+		path = filepath.Join(s.Home, path[2:])
+	case !filepath.IsAbs(path):
+		path = filepath.Join(s.WorkDir, path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		s.t.Fatalf("unable to create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		s.t.Fatalf("unable to write %s: %v", path, err)
+	}
+	return path
+}
+
+// Run executes the command with the arguments, returning the combined output. When the command is a subcommand, the
+// root command is executed
+func (s *Sandbox) Run(cmd *cobra.Command, args ...string) (string, error) {
+	s.t.Helper()
+
+	// cobra uses the process arguments when args are nil
+	args = append(commandPath(cmd), args...)
+	root := cmd.Root()
+
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+// commandPath returns the names of the commands from the root command to the command, excluding the root
+func commandPath(cmd *cobra.Command) []string {
+	out := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		out = append([]string{c.Name()}, out...)
+	}
+	return out
+}
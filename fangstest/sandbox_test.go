@@ -0,0 +1,123 @@
+package fangstest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/fangs"
+)
+
+type appConfig struct {
+	Name  string `yaml:"name" json:"name" mapstructure:"name"`
+	Depth int    `yaml:"depth" json:"depth" mapstructure:"depth"`
+}
+
+func (a *appConfig) DescribeFields(d fangs.FieldDescriptionSet) {
+	d.Add(&a.Name, "the name to use")
+}
+
+func Test_Sandbox(t *testing.T) {
+	t.Parallel()
+
+	s := New(t, "app")
+	cfg := s.Config()
+
+	s.WriteFile("~/.app.yaml", "name: home\ndepth: 1\n")
+	s.WriteFile(".app.yaml", "name: local\n")
+	s.WriteFile(filepath.Join(s.XDGConfigHome, "app", "config.yaml"), "depth: 3\n")
+	s.Setenv("APP_DEPTH", "5")
+
+	loaded := &appConfig{}
+	cmd := &cobra.Command{
+		Use: "app",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return fangs.Load(cfg, cmd, loaded)
+		},
+	}
+	cmd.Flags().StringVar(&loaded.Name, "name", "", "the name")
+
+	_, err := s.Run(cmd)
+	require.NoError(t, err)
+	require.Equal(t, appConfig{Name: "local", Depth: 5}, *loaded)
+
+	_, err = s.Run(cmd, "--name", "flag")
+	require.NoError(t, err)
+	require.Equal(t, appConfig{Name: "flag", Depth: 5}, *loaded)
+
+	s.AssertSummary("summary", cfg, cmd, &appConfig{Name: "default", Depth: 2})
+}
+
+func Test_SandboxIsolatesEnvironment(t *testing.T) {
+	t.Setenv("APP_NAME", "process")
+
+	s := New(t, "app")
+	cmd := &cobra.Command{Use: "app"}
+	loaded := &appConfig{}
+	require.NoError(t, fangs.Load(s.Config(), cmd, loaded))
+	require.Equal(t, "", loaded.Name)
+
+	s.Setenv("APP_NAME", "sandbox")
+	require.NoError(t, fangs.Load(s.Config(), cmd, loaded))
+	require.Equal(t, "sandbox", loaded.Name)
+}
+
+func Test_SandboxLoad(t *testing.T) {
+	t.Parallel()
+
+	s := New(t, "app")
+	s.WriteFile(".app.yaml", "name: local\n")
+	s.WriteFile(filepath.Join(".app", "config.yaml"), "depth: 3\n")
+	s.Setenv("APP_DEPTH", "5")
+
+	// the working directory is the sandbox without changing the process working directory
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(wd, ".app.yaml"))
+
+	defaults := &appConfig{Name: "default", Depth: 1}
+	require.Equal(t, &appConfig{Name: "local", Depth: 5}, Load(s, nil, defaults))
+	require.Equal(t, &appConfig{Name: "default", Depth: 1}, defaults)
+
+	AssertLoad(s, nil, defaults, &appConfig{Name: "local", Depth: 5})
+}
+
+func Test_SandboxIgnoresSystemDir(t *testing.T) {
+	t.Parallel()
+
+	s := New(t, "app")
+
+	// all locations searched are within the sandbox, so configuration in the host /etc is ignored
+	for _, location := range fangs.SummarizeLocations(s.Config()) {
+		if filepath.IsAbs(location) {
+			require.True(t, strings.HasPrefix(location, s.Dir+string(filepath.Separator)), "location outside sandbox: %s", location)
+		}
+	}
+}
+
+func Test_SandboxRunSubcommand(t *testing.T) {
+	t.Parallel()
+
+	s := New(t, "app")
+
+	var args []string
+	root := &cobra.Command{Use: "app"}
+	sub := &cobra.Command{
+		Use: "sub",
+		RunE: func(cmd *cobra.Command, a []string) error {
+			args = a
+			cmd.Print("ran sub")
+			return nil
+		},
+	}
+	root.AddCommand(sub)
+
+	out, err := s.Run(sub, "one", "two")
+	require.NoError(t, err)
+	require.Equal(t, "ran sub", out)
+	require.Equal(t, []string{"one", "two"}, args)
+}
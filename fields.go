@@ -0,0 +1,41 @@
+package fangs
+
+import (
+	"reflect"
+	"strings"
+)
+
+// fieldKey returns the configuration key of the struct field from the Config.TagName tag, or the field name when not
+// tagged. squash is true for embedded structs tagged with the ,squash option, whose fields are keyed at the same level
+// as the field itself. ok is false for fields which are not configuration values: those which are unexported or tagged
+// with "-". This is how values are keyed when loading configuration
+func fieldKey(cfg Config, f reflect.StructField) (key string, squash bool, ok bool) {
+	if !includeField(f) {
+		return "", false, false
+	}
+	tag, tagged := f.Tag.Lookup(cfg.TagName)
+	if !tagged {
+		return f.Name, false, true
+	}
+	parts := strings.Split(tag, ",")
+	switch {
+	case parts[0] == "-":
+		return "", false, false
+	case contains(parts, "squash"):
+		return "", true, true
+	case parts[0] == "":
+		return f.Name, false, true
+	}
+	return parts[0], false, true
+}
+
+// outputFieldKey returns the configuration key of the struct field the same as fieldKey, for fields which are output
+// such as by summaries and schemas: fields tagged yaml:"-" are also excluded, when the Config.TagName is not yaml
+func outputFieldKey(cfg Config, f reflect.StructField) (key string, squash bool, ok bool) {
+	if cfg.TagName != "yaml" {
+		if tag, ok := f.Tag.Lookup("yaml"); ok && strings.Split(tag, ",")[0] == "-" {
+			return "", false, false
+		}
+	}
+	return fieldKey(cfg, f)
+}
@@ -0,0 +1,56 @@
+package fangs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fieldKey(t *testing.T) {
+	type embedded struct{}
+	type s struct {
+		Untagged   string
+		Tagged     string   `mapstructure:"tagged"`
+		Empty      string   `mapstructure:",omitempty"`
+		Ignored    string   `mapstructure:"-"`
+		YAMLOnly   string   `mapstructure:"yaml-only" yaml:"-"`
+		Squashed   embedded `mapstructure:",squash"`
+		unexported string
+	}
+
+	type result struct {
+		key    string
+		squash bool
+		ok     bool
+	}
+
+	tests := []struct {
+		field  string
+		load   result
+		output result
+	}{
+		{field: "Untagged", load: result{"Untagged", false, true}, output: result{"Untagged", false, true}},
+		{field: "Tagged", load: result{"tagged", false, true}, output: result{"tagged", false, true}},
+		{field: "Empty", load: result{"Empty", false, true}, output: result{"Empty", false, true}},
+		{field: "Ignored", load: result{}, output: result{}},
+		{field: "YAMLOnly", load: result{"yaml-only", false, true}, output: result{}},
+		{field: "Squashed", load: result{"", true, true}, output: result{"", true, true}},
+		{field: "unexported", load: result{}, output: result{}},
+	}
+
+	cfg := NewConfig("app")
+	typ := reflect.TypeOf(s{})
+	for _, test := range tests {
+		t.Run(test.field, func(t *testing.T) {
+			f, ok := typ.FieldByName(test.field)
+			require.True(t, ok)
+
+			key, squash, ok := fieldKey(cfg, f)
+			require.Equal(t, test.load, result{key, squash, ok})
+
+			key, squash, ok = outputFieldKey(cfg, f)
+			require.Equal(t, test.output, result{key, squash, ok})
+		})
+	}
+}
@@ -2,34 +2,37 @@ package fangs
 
 import (
 	"fmt"
+	"io/fs"
 	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 
-	"github.com/adrg/xdg"
 	"github.com/spf13/viper"
-
-	"github.com/anchore/go-homedir"
 )
 
 type Finder func(cfg Config) []string
 
 // FindConfigYamlInCwd looks for ./config.yaml -- NOTE: this is not part of the default behavior
-func FindConfigYamlInCwd(_ Config) []string {
-	return []string{"./config.yaml"}
+func FindConfigYamlInCwd(cfg Config) []string {
+	return []string{workDirPath(cfg, "./config.yaml")}
 }
 
 // FindInCwd looks for ./.<appname>.<ext>
 func FindInCwd(cfg Config) []string {
-	return findConfigFiles(".", "."+cfg.AppName)
+	return findConfigFiles(workDirPath(cfg, "."), "."+cfg.AppName)
 }
 
 // FindInAppNameSubdir looks for ./.<appname>/config.<ext>
 func FindInAppNameSubdir(cfg Config) []string {
-	return findConfigFiles("."+cfg.AppName, "config")
+	return findConfigFiles(workDirPath(cfg, "."+cfg.AppName), "config")
 }
 
 // FindInHomeDir looks for ~/.<appname>.<ext>
 func FindInHomeDir(cfg Config) []string {
-	home, err := homedir.Dir()
+	home, err := homeDir(cfg)
 	if err != nil {
 		cfg.Logger.Debugf("unable to determine home dir: %w", err)
 		return nil
@@ -39,13 +42,125 @@ func FindInHomeDir(cfg Config) []string {
 
 // FindInXDG looks for <appname>/config.yaml in xdg locations, starting with xdg home config dir then moving upwards
 func FindInXDG(cfg Config) (out []string) {
-	dirs := []string{path.Join(xdg.ConfigHome, cfg.AppName)}
-	for _, dir := range xdg.ConfigDirs {
-		dirs = append(dirs, path.Join(dir, cfg.AppName))
+	for _, dir := range xdgDirs(cfg) {
+		out = append(out, findConfigFiles(path.Join(dir, cfg.AppName), "config")...)
+	}
+	return
+}
+
+// xdgDirs returns the xdg config home dir followed by the xdg config dirs
+func xdgDirs(cfg Config) (out []string) {
+	configHome, configDirs := xdgConfigDirs(cfg)
+	if configHome != "" {
+		out = append(out, configHome)
+	}
+	return append(out, configDirs...)
+}
+
+// systemConfigDir is the directory searched for system-wide configuration
+var systemConfigDir = "/etc"
+
+// FindInSystemDir looks for system-wide configuration: /etc/<appname>/config.<ext> and /etc/<appname>.<ext>
+func FindInSystemDir(cfg Config) (out []string) {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	out = append(out, findConfigFiles(path.Join(systemConfigDir, cfg.AppName), "config")...)
+	out = append(out, findConfigFiles(systemConfigDir, cfg.AppName)...)
+	return
+}
+
+// FindInParentDirs returns a Finder that looks for .<appname>.<ext> and .<appname>/config.<ext> in the working
+// directory and each parent directory up to the filesystem root, nearest first, so configuration for a project is
+// found when running from any of its subdirectories. If stop markers are provided, such as ".git", the search stops at
+// the first directory containing any of them
+func FindInParentDirs(stopMarkers ...string) Finder {
+	return func(cfg Config) (out []string) {
+		dir, err := workDir(cfg)
+		if err != nil {
+			cfg.Logger.Debugf("unable to determine working dir: %w", err)
+			return nil
+		}
+		for {
+			out = append(out, findConfigFiles(dir, "."+cfg.AppName)...)
+			out = append(out, findConfigFiles(filepath.Join(dir, "."+cfg.AppName), "config")...)
+			if containsAny(configFS(cfg), dir, stopMarkers) {
+				return
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return
+			}
+			dir = parent
+		}
+	}
+}
+
+// containsAny returns true if any of the names exist in the directory
+func containsAny(fsys fs.FS, dir string, names []string) bool {
+	for _, name := range names {
+		if _, err := statFS(fsys, filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FindInConfD looks for drop-in configuration fragments: ./.<appname>/conf.d/*.<ext>, ~/.<appname>/conf.d/*.<ext> and
+// <appname>/conf.d/*.<ext> in xdg locations. Fragments in each directory are returned in reverse lexical order, so
+// later fragments such as 90-local.yaml take precedence over earlier fragments such as 10-defaults.yaml
+// -- NOTE: this is not part of the default behavior, see Config.WithConfD
+func FindInConfD(cfg Config) (out []string) {
+	for _, dir := range confDDirs(cfg) {
+		out = append(out, findConfigFragments(configFS(cfg), dir)...)
+	}
+	return
+}
+
+// confDDirs returns the conf.d directories searched by FindInConfD, in order of precedence
+func confDDirs(cfg Config) []string {
+	dirs := []string{workDirPath(cfg, path.Join("."+cfg.AppName, "conf.d"))}
+	home, err := homeDir(cfg)
+	if err != nil {
+		cfg.Logger.Debugf("unable to determine home dir: %w", err)
+	} else {
+		dirs = append(dirs, path.Join(home, "."+cfg.AppName, "conf.d"))
+	}
+	for _, dir := range xdgDirs(cfg) {
+		dirs = append(dirs, path.Join(dir, cfg.AppName, "conf.d"))
+	}
+	return dirs
+}
+
+// indexOfFinder returns the index of the finder in the finders, or -1 if not found
+func indexOfFinder(finders []Finder, finder Finder) int {
+	for i, f := range finders {
+		if reflect.ValueOf(f).Pointer() == reflect.ValueOf(finder).Pointer() {
+			return i
+		}
+	}
+	return -1
+}
+
+// findConfigFragments returns all configuration files in the directory with supported extensions, in reverse lexical
+// order
+func findConfigFragments(fsys fs.FS, dir string) (out []string) {
+	entries, err := readDirFS(fsys, dir)
+	if err != nil {
+		return nil
 	}
-	for _, dir := range dirs {
-		out = append(out, findConfigFiles(dir, "config")...)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.TrimPrefix(path.Ext(entry.Name()), ".")
+		if !slices.Contains(viper.SupportedExts, ext) {
+			continue
+		}
+		out = append(out, path.Join(dir, entry.Name()))
 	}
+	// entries are sorted by name, the last fragment has the highest priority
+	slices.Reverse(out)
 	return
 }
 
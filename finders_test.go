@@ -0,0 +1,182 @@
+package fangs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/go-homedir"
+)
+
+// setupFinderDirs uses a temporary directory as the working directory, home directory and xdg directories
+func setupFinderDirs(t *testing.T) string {
+	t.Helper()
+	t.Cleanup(func() {
+		xdg.Reload()
+	})
+
+	restoreCache(t)
+	homedir.SetCacheEnable(false)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg-home"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "xdg-dir"))
+
+	xdg.Reload()
+
+	return dir
+}
+
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for file, contents := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0700))
+		require.NoError(t, os.WriteFile(file, []byte(contents), 0600))
+	}
+}
+
+func Test_FindInConfD(t *testing.T) {
+	dir := setupFinderDirs(t)
+
+	writeFiles(t, map[string]string{
+		".app/conf.d/10-defaults.yaml":                "name: cwd-10\nitems: [cwd-10]\n",
+		".app/conf.d/90-local.yaml":                   "name: cwd-90\nitems: [cwd-90]\n",
+		".app/conf.d/README.md":                       "not configuration",
+		".app/conf.d/50-dir.yaml/ignored.yaml":        "name: ignored\n",
+		"home/.app/conf.d/50-home.yaml":               "depth: 5\nitems: [home]\n",
+		"xdg-home/app/conf.d/50-xdg.json":             `{"depth": 10, "enabled": true}`,
+		"xdg-dir/app/conf.d/50-xdg.yaml":              "label: xdg-dir\n",
+		"xdg-dir/app/conf.d.yaml":                     "label: not-conf.d\n",
+		filepath.Join(dir, "other/app/conf.d/x.yaml"): "label: other\n",
+	})
+
+	cfg := NewConfig("app")
+	require.Equal(t, []string{
+		".app/conf.d/90-local.yaml",
+		".app/conf.d/10-defaults.yaml",
+		filepath.Join(dir, "home/.app/conf.d/50-home.yaml"),
+		filepath.Join(dir, "xdg-home/app/conf.d/50-xdg.json"),
+		filepath.Join(dir, "xdg-dir/app/conf.d/50-xdg.yaml"),
+	}, FindInConfD(cfg))
+
+	cfg = cfg.WithConfD()
+	require.Len(t, cfg.Finders, len(NewConfig("app").Finders)+1)
+	require.Equal(t, len(cfg.Finders)-2, indexOfFinder(cfg.Finders, FindInConfD))
+	require.Equal(t, len(cfg.Finders)-1, indexOfFinder(cfg.Finders, FindInSystemDir))
+
+	// without FindInSystemDir, fragments are searched last
+	other := Config{Finders: []Finder{FindInCwd}}.WithConfD()
+	require.Equal(t, 1, indexOfFinder(other.Finders, FindInConfD))
+
+	orig := systemConfigDir
+	systemConfigDir = filepath.Join(dir, "etc")
+	t.Cleanup(func() {
+		systemConfigDir = orig
+	})
+
+	// fragments have lower precedence than other configuration files, but higher than system-wide configuration
+	writeFiles(t, map[string]string{
+		".app.yaml":    "name: main\n",
+		"etc/app.yaml": "label: system\nitems: [system]\n",
+	})
+
+	c := &includeConfig{}
+	require.NoError(t, Load(cfg, &cobra.Command{}, c))
+	require.Equal(t, &includeConfig{
+		Name:    "main",
+		Depth:   5,
+		Enabled: true,
+		Label:   "xdg-dir",
+		Items:   []string{"cwd-90", "cwd-10", "home", "system"},
+	}, c)
+}
+
+func Test_FindInParentDirs(t *testing.T) {
+	dir := setupFinderDirs(t)
+
+	writeFiles(t, map[string]string{
+		"repo/.git/HEAD":               "ref: refs/heads/main\n",
+		"repo/.app.yaml":               "name: repo\nitems: [repo]\n",
+		"repo/sub/.app/config.yaml":    "depth: 2\nitems: [sub]\n",
+		"repo/sub/dir/.app.yaml":       "label: dir\nitems: [dir]\n",
+		".app.yaml":                    "enabled: true\n",
+		"repo/sub/dir/nested/.keep":    "",
+		"repo/sub/dir/nested/.app.bad": "not configuration",
+	})
+
+	t.Chdir(filepath.Join(dir, "repo", "sub", "dir", "nested"))
+
+	c := &includeConfig{}
+	cfg := NewConfig("app")
+	cfg.Finders = []Finder{FindInCwd, FindInParentDirs(".git")}
+	require.NoError(t, Load(cfg, &cobra.Command{}, c))
+	require.Equal(t, &includeConfig{
+		Name:  "repo",
+		Depth: 2,
+		Label: "dir",
+		Items: []string{"dir", "sub", "repo"},
+	}, c)
+
+	// without stop markers, search up to the filesystem root
+	c = &includeConfig{}
+	cfg.Finders = []Finder{FindInParentDirs()}
+	require.NoError(t, Load(cfg, &cobra.Command{}, c))
+	require.True(t, c.Enabled)
+
+	locations := FindInParentDirs(".git")(cfg)
+	exts := viper.SupportedExts
+	require.Len(t, locations, 4*2*len(exts))
+	require.Equal(t, filepath.Join(dir, "repo", "sub", "dir", "nested", ".app."+exts[0]), locations[0])
+	require.Equal(t, filepath.Join(dir, "repo", ".app", "config."+exts[len(exts)-1]), locations[len(locations)-1])
+}
+
+func Test_findConfigurationFilesDeduplicates(t *testing.T) {
+	setupFinderDirs(t)
+
+	writeFiles(t, map[string]string{
+		".app.yaml": "items: [cwd]\n",
+	})
+
+	cfg := NewConfig("app")
+	cfg.Finders = []Finder{FindInCwd, FindInParentDirs()}
+	files, err := findConfigurationFiles(cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{".app.yaml"}, files)
+}
+
+func Test_FindInSystemDir(t *testing.T) {
+	dir := setupFinderDirs(t)
+
+	orig := systemConfigDir
+	systemConfigDir = filepath.Join(dir, "etc")
+	t.Cleanup(func() {
+		systemConfigDir = orig
+	})
+
+	writeFiles(t, map[string]string{
+		"etc/app/config.yaml":       "name: etc-config\nitems: [etc-config]\n",
+		"etc/app.yaml":              "name: etc\ndepth: 3\nitems: [etc]\n",
+		"xdg-home/app/config.yaml":  "label: xdg\n",
+		"etc/other-app/config.yaml": "name: other\n",
+	})
+
+	locations := FindInSystemDir(NewConfig("app"))
+	require.Contains(t, locations, filepath.Join(dir, "etc", "app", "config.yaml"))
+	require.Contains(t, locations, filepath.Join(dir, "etc", "app.yaml"))
+
+	c := &includeConfig{Label: "default"}
+	require.NoError(t, Load(NewConfig("app"), &cobra.Command{}, c))
+	require.Equal(t, &includeConfig{
+		Name:  "etc-config",
+		Depth: 3,
+		Label: "xdg",
+		Items: []string{"etc-config", "etc"},
+	}, c)
+}
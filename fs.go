@@ -0,0 +1,175 @@
+package fangs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NewOSFS returns an fs.FS for the OS filesystem which, unlike os.DirFS, accepts both absolute paths and paths
+// relative to the working directory, as returned by Finders
+func NewOSFS() fs.FS {
+	return osFS{}
+}
+
+// NewUnionFS returns an fs.FS which combines the filesystems, such as an embed.FS with default configuration and the
+// OS filesystem. Files are opened from the first filesystem they exist in, and directory entries are combined.
+// Absolute paths are resolved relative to the root of filesystems which only accept unrooted paths, such as an embed.FS
+func NewUnionFS(filesystems ...fs.FS) fs.FS {
+	return unionFS(filesystems)
+}
+
+// rawPathFS is implemented by filesystems that accept OS paths, rather than only paths valid for fs.FS
+type rawPathFS interface {
+	fs.FS
+	rawPaths()
+}
+
+// fsName returns the name to use for an OS path within the filesystem: filesystems which only accept paths valid for
+// fs.FS are passed a clean, unrooted, slash-separated path
+func fsName(fsys fs.FS, name string) string {
+	if _, ok := fsys.(rawPathFS); ok {
+		return name
+	}
+	name = strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// configFS returns the filesystem configuration files are read from
+func configFS(cfg Config) fs.FS {
+	if cfg.FS == nil {
+		return osFS{}
+	}
+	return cfg.FS
+}
+
+func statFS(fsys fs.FS, name string) (fs.FileInfo, error) {
+	return fs.Stat(fsys, fsName(fsys, name))
+}
+
+func readFileFS(fsys fs.FS, name string) ([]byte, error) {
+	return fs.ReadFile(fsys, fsName(fsys, name))
+}
+
+func readDirFS(fsys fs.FS, name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(fsys, fsName(fsys, name))
+}
+
+func globFS(fsys fs.FS, pattern string) ([]string, error) {
+	return fs.Glob(fsys, fsName(fsys, pattern))
+}
+
+func fileExistsFS(fsys fs.FS, name string) bool {
+	fi, err := statFS(fsys, name)
+	return err == nil && !fi.IsDir()
+}
+
+type osFS struct{}
+
+var _ interface {
+	rawPathFS
+	fs.StatFS
+	fs.ReadFileFS
+	fs.ReadDirFS
+	fs.GlobFS
+} = osFS{}
+
+func (osFS) rawPaths() {}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+type unionFS []fs.FS
+
+var _ interface {
+	rawPathFS
+	fs.StatFS
+	fs.ReadFileFS
+	fs.ReadDirFS
+} = unionFS(nil)
+
+func (unionFS) rawPaths() {}
+
+func (u unionFS) Open(name string) (fs.File, error) {
+	return first(u, name, func(fsys fs.FS, name string) (fs.File, error) {
+		return fsys.Open(name)
+	})
+}
+
+func (u unionFS) Stat(name string) (fs.FileInfo, error) {
+	return first(u, name, fs.Stat)
+}
+
+func (u unionFS) ReadFile(name string) ([]byte, error) {
+	return first(u, name, fs.ReadFile)
+}
+
+// ReadDir returns the entries of the directory from all filesystems, entries in earlier filesystems take precedence
+func (u unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	found := false
+	entries := map[string]fs.DirEntry{}
+	for _, fsys := range u {
+		dirEntries, err := fs.ReadDir(fsys, fsName(fsys, name))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range dirEntries {
+			if _, ok := entries[entry.Name()]; !ok {
+				entries[entry.Name()] = entry
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	out := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name() < out[j].Name()
+	})
+	return out, nil
+}
+
+// first returns the result from the first filesystem the name exists in
+func first[T any](filesystems []fs.FS, name string, fn func(fs.FS, string) (T, error)) (T, error) {
+	for _, fsys := range filesystems {
+		out, err := fn(fsys, fsName(fsys, name))
+		if err != nil && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return out, err
+	}
+	var zero T
+	return zero, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
@@ -0,0 +1,101 @@
+package fangs
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UnionFS(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, "etc/app.yaml"):   "name: os\n",
+		filepath.Join(dir, "etc/other.yaml"): "name: other\n",
+	})
+
+	embedded := fstest.MapFS{
+		dir[1:] + "/etc/app.yaml":   {Data: []byte("name: embedded\n")},
+		dir[1:] + "/etc/extra.yaml": {Data: []byte("name: extra\n")},
+		"defaults/app.yaml":         {Data: []byte("name: defaults\n")},
+	}
+
+	fsys := NewUnionFS(embedded, NewOSFS())
+
+	// the first filesystem containing a file is used, absolute paths are relative to the root of the embedded files
+	contents, err := readFileFS(fsys, filepath.Join(dir, "etc/app.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "name: embedded\n", string(contents))
+
+	contents, err = readFileFS(fsys, filepath.Join(dir, "etc/other.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "name: other\n", string(contents))
+
+	contents, err = readFileFS(fsys, "./defaults/app.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "name: defaults\n", string(contents))
+
+	_, err = statFS(fsys, filepath.Join(dir, "missing.yaml"))
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+
+	entries, err := readDirFS(fsys, filepath.Join(dir, "etc"))
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.Equal(t, []string{"app.yaml", "extra.yaml", "other.yaml"}, names)
+
+	matches, err := globFS(fsys, filepath.Join(dir, "etc", "*.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "etc/app.yaml"),
+		filepath.Join(dir, "etc/extra.yaml"),
+		filepath.Join(dir, "etc/other.yaml"),
+	}, matches)
+}
+
+func Test_LoadFromFS(t *testing.T) {
+	type config struct {
+		Name  string   `mapstructure:"name"`
+		Depth int      `mapstructure:"depth"`
+		Items []string `mapstructure:"items"`
+	}
+
+	embedded := fstest.MapFS{
+		"defaults/app.yaml":      {Data: []byte("include: [base/*.yaml]\nname: defaults\nitems: [defaults]\n")},
+		"defaults/base/one.yaml": {Data: []byte("depth: 1\nitems: [one]\n")},
+		".app/conf.d/10-x.yaml":  {Data: []byte("name: conf.d\ndepth: 10\n")},
+		"bad/app.yaml":           {Data: []byte("name: bad\ndepth: deep\n")},
+	}
+
+	cfg := NewConfig("app")
+	cfg.FS = embedded
+	cfg.IncludeKey = "include"
+	cfg.Files = []string{"defaults/app.yaml"}
+
+	c := &config{}
+	require.NoError(t, Load(cfg, &cobra.Command{}, c))
+	require.Equal(t, &config{Name: "defaults", Depth: 1, Items: []string{"defaults", "one"}}, c)
+
+	// finders search the filesystem
+	cfg.Files = nil
+	cfg.Finders = []Finder{FindInConfD}
+	c = &config{}
+	require.NoError(t, Load(cfg, &cobra.Command{}, c))
+	require.Equal(t, &config{Name: "conf.d", Depth: 10}, c)
+
+	// load error locations are found in the filesystem
+	cfg.Files = []string{"bad/app.yaml"}
+	err := Load(cfg, &cobra.Command{}, &config{})
+	var loadErr *LoadError
+	require.ErrorAs(t, err, &loadErr)
+	require.Equal(t, 2, loadErr.Issues[0].Line)
+
+	cfg.Files = []string{"missing.yaml"}
+	require.ErrorContains(t, Load(cfg, &cobra.Command{}, &config{}), "file does not exist: missing.yaml")
+}
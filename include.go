@@ -0,0 +1,183 @@
+package fangs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// configReader reads configuration files into a single viper instance, including any files they include
+type configReader struct {
+	cfg   Config
+	prov  Provenance
+	merge mergeConfig
+	v     *viper.Viper
+
+	// files are all the files read, in the order read
+	files []string
+	read  set[string]
+}
+
+func newConfigReader(cfg Config, prov Provenance) *configReader {
+	return &configReader{
+		cfg:  cfg,
+		prov: prov,
+		v:    viper.New(),
+		read: set[string]{},
+	}
+}
+
+// readFile reads the configuration file and merges it with the configuration already read, followed by any files
+// it includes. including is the chain of files including this file, used to detect cycles
+func (r *configReader) readFile(f string, including []string) error {
+	abs, err := filepath.Abs(f)
+	if err != nil {
+		return fmt.Errorf("unable to resolve path: %s: %w", f, err)
+	}
+	for _, parent := range including {
+		if parent == abs {
+			return fmt.Errorf("configuration files include each other: %s", strings.Join(append(including, abs), " -> "))
+		}
+	}
+	if r.read.contains(abs) {
+		// already merged, reading again would append slice values multiple times
+		r.cfg.Logger.Debugf("configuration file already read: %s", f)
+		return nil
+	}
+	r.read.add(abs)
+	r.files = append(r.files, f)
+
+	newV, err := readConfigFile(r.cfg, f)
+	if err != nil {
+		return err
+	}
+
+	all := r.v.AllSettings()
+	incoming := newV.AllSettings()
+
+	includes, err := r.includes(f, incoming)
+	if err != nil {
+		return err
+	}
+
+	// files are read in priority order, so the first file to define a value is where it came from
+	for _, key := range flattenKeys(incoming) {
+		if !r.prov.covers(key) {
+			r.prov.set(key, Origin{Kind: SourceFile, File: f})
+		}
+	}
+
+	// files are read in priority order, so the values already read take precedence over the incoming values, by
+	// default slices will have high priority entries first and retain lower priority entries
+	mergeValues(incoming, all, r.merge, MergePrepend)
+	all = incoming
+
+	// viper merge will overwrite same keys, we have appended slices to the previous config in the previous step
+	err = r.v.MergeConfigMap(all)
+	if err != nil {
+		return err
+	}
+
+	for _, include := range includes {
+		if err := r.readFile(include, append(including, abs)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readConfigFile reads the configuration file from the configured filesystem, based on the file extension
+func readConfigFile(cfg Config, f string) (*viper.Viper, error) {
+	v := viper.New()
+
+	ext := strings.TrimPrefix(filepath.Ext(f), ".")
+	if !slices.Contains(viper.SupportedExts, ext) {
+		return nil, fmt.Errorf("unable to load config: %w", viper.UnsupportedConfigError(ext))
+	}
+	v.SetConfigType(ext)
+
+	contents, err := readFileFS(configFS(cfg), f)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			cfg.Logger.Debug("no config file found, using defaults")
+			return v, nil
+		}
+		return nil, fmt.Errorf("unable to load config: %w", err)
+	}
+
+	err = v.ReadConfig(bytes.NewReader(contents))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load config: %w", err)
+	}
+	return v, nil
+}
+
+// includes removes the include key from the settings, returning the files to include: relative paths are resolved
+// against the directory of the including file, and glob patterns are expanded in lexical order
+func (r *configReader) includes(f string, settings map[string]any) (out []string, err error) {
+	if r.cfg.IncludeKey == "" {
+		return nil, nil
+	}
+	key := strings.ToLower(r.cfg.IncludeKey)
+	value, ok := settings[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(settings, key)
+
+	var patterns []string
+	switch value := value.(type) {
+	case nil:
+	case string:
+		patterns = append(patterns, value)
+	case []any:
+		for _, entry := range value {
+			pattern, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid '%s' entry in %s, expected a path: %v", r.cfg.IncludeKey, f, entry)
+			}
+			patterns = append(patterns, pattern)
+		}
+	default:
+		return nil, fmt.Errorf("invalid '%s' in %s, expected a path or list of paths: %v", r.cfg.IncludeKey, f, value)
+	}
+
+	for _, pattern := range patterns {
+		pattern, err = expandHome(r.cfg, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("unable to expand path: %s", pattern)
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(f), pattern)
+		}
+
+		if !isGlob(pattern) {
+			if !fileExistsFS(configFS(r.cfg), pattern) {
+				return nil, fmt.Errorf("included file does not exist: %s, included from: %s", pattern, f)
+			}
+			out = append(out, pattern)
+			continue
+		}
+
+		matches, err := globFS(configFS(r.cfg), pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern: %s, included from: %s: %w", pattern, f, err)
+		}
+		for _, match := range matches {
+			if fileExistsFS(configFS(r.cfg), match) {
+				out = append(out, match)
+			}
+		}
+	}
+	return out, nil
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
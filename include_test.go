@@ -0,0 +1,78 @@
+package fangs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+type includeConfig struct {
+	Name    string   `mapstructure:"name"`
+	Depth   int      `mapstructure:"depth"`
+	Enabled bool     `mapstructure:"enabled"`
+	Label   string   `mapstructure:"label"`
+	Items   []string `mapstructure:"items"`
+}
+
+func Test_Include(t *testing.T) {
+	cfg := NewConfig("app")
+	cfg.IncludeKey = "include"
+	cfg.Strict = true
+	cfg.Files = []string{"test-fixtures/include/main.yaml"}
+
+	c := &includeConfig{}
+	prov, err := LoadWithProvenance(cfg, &cobra.Command{}, c)
+	require.NoError(t, err)
+
+	require.Equal(t, &includeConfig{
+		Name:    "main",
+		Depth:   1,
+		Enabled: true,
+		Label:   "other",
+		Items:   []string{"main", "first", "second"},
+	}, c)
+
+	require.Equal(t, filepath.Join("test-fixtures", "include", "base", "1-first.yaml"), prov["depth"].File)
+	require.Equal(t, filepath.Join("test-fixtures", "include", "base", "2-second.yaml"), prov["enabled"].File)
+	require.Equal(t, filepath.Join("test-fixtures", "include", "other.yaml"), prov["label"].File)
+}
+
+func Test_IncludeDisabled(t *testing.T) {
+	cfg := NewConfig("app")
+	cfg.Files = []string{"test-fixtures/include/main.yaml"}
+
+	c := &includeConfig{}
+	require.NoError(t, Load(cfg, &cobra.Command{}, c))
+	require.Equal(t, &includeConfig{
+		Name:  "main",
+		Items: []string{"main"},
+	}, c)
+}
+
+func Test_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		file    string
+		wantErr string
+	}{
+		{
+			file:    "test-fixtures/include/cycle/a.yaml",
+			wantErr: "configuration files include each other: ",
+		},
+		{
+			file:    "test-fixtures/include/missing.yaml",
+			wantErr: "included file does not exist: " + filepath.Join("test-fixtures", "include", "does-not-exist.yaml"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			cfg := NewConfig("app")
+			cfg.IncludeKey = "include"
+			cfg.Files = []string{test.file}
+
+			err := Load(cfg, &cobra.Command{}, &includeConfig{})
+			require.ErrorContains(t, err, test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package fangs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// WriteConfigFile writes a starter configuration file with the current values of the configurations, which should be
+// the defaults, along with all descriptions and environment variables the same as SummarizeCommand. The file is written
+// to the path, if provided, otherwise to the first YAML location found by the Finders that either exists or is in a
+// writable directory. A leading ~ is expanded using Config.LookupEnv and relative paths are within Config.WorkDir, but
+// the file is always written to the OS filesystem, so Config.FS is not used. An existing file is not replaced unless
+// overwrite is true. Returns the path of the written file
+func WriteConfigFile(cfg Config, cmd *cobra.Command, path string, overwrite bool, values ...any) (string, error) {
+	if path == "" {
+		path = initLocation(cfg)
+		if path == "" {
+			return "", fmt.Errorf("unable to find a writable configuration file location")
+		}
+	}
+
+	file, err := expandHome(cfg, path)
+	if err != nil {
+		return "", fmt.Errorf("unable to expand path: %s: %w", path, err)
+	}
+	file = workDirPath(cfg, file)
+
+	if !overwrite && fileExists(file) {
+		return "", fmt.Errorf("configuration file already exists: %s", file)
+	}
+
+	contents := SummarizeCommand(cfg, cmd, nil, values...)
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return "", fmt.Errorf("unable to create directory for configuration file: %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+		return "", fmt.Errorf("unable to write configuration file: %s: %w", file, err)
+	}
+	return file, nil
+}
+
+// initLocation returns the first YAML location found by the Finders which exists, so it is the file that would be
+// loaded, or is in a directory that is writable
+func initLocation(cfg Config) string {
+	for _, location := range SummarizeLocations(cfg) {
+		switch strings.ToLower(filepath.Ext(location)) {
+		case ".yaml", ".yml":
+		default:
+			continue
+		}
+		file, err := expandHome(cfg, location)
+		if err != nil {
+			continue
+		}
+		if fileExists(file) || dirWritable(filepath.Dir(file)) {
+			return file
+		}
+	}
+	return ""
+}
+
+// dirWritable returns true if a file can be created in the directory, or in the closest existing parent directory
+// if the directory does not exist
+func dirWritable(dir string) bool {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return false
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if !os.IsNotExist(err) || parent == dir {
+			return false
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".fangs-*")
+	if err != nil {
+		return false
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return true
+}
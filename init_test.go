@@ -0,0 +1,132 @@
+package fangs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteConfigFile(t *testing.T) {
+	type sub struct {
+		Enabled bool `mapstructure:"enabled" description:"enable the thing"`
+	}
+	type config struct {
+		Name string `mapstructure:"name"`
+		Sub  sub    `mapstructure:"sub"`
+	}
+
+	cmd := &cobra.Command{}
+	cfg := NewConfig("app")
+	c := &config{Name: "default"}
+
+	expected := `# (env: APP_NAME)
+name: 'default'
+
+sub:
+  # enable the thing (env: APP_SUB_ENABLED)
+  enabled: false
+
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "app.yaml")
+
+	file, err := WriteConfigFile(cfg, cmd, path, false, c)
+	require.NoError(t, err)
+	require.Equal(t, path, file)
+
+	contents, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(contents))
+
+	// written file loads the same values
+	loaded := &config{}
+	cfg.Files = []string{file}
+	require.NoError(t, Load(cfg, cmd, loaded))
+	require.Equal(t, c, loaded)
+
+	// refuse to overwrite
+	c.Name = "changed"
+	_, err = WriteConfigFile(cfg, cmd, path, false, c)
+	require.ErrorContains(t, err, "configuration file already exists")
+
+	contents, err = os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(contents))
+
+	// overwrite when forced
+	_, err = WriteConfigFile(cfg, cmd, path, true, c)
+	require.NoError(t, err)
+
+	contents, err = os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "name: 'changed'")
+}
+
+func Test_WriteConfigFileLocation(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	type config struct {
+		Name string `mapstructure:"name"`
+	}
+
+	cfg := NewConfig("app")
+	cfg.Finders = []Finder{
+		func(_ Config) []string {
+			// not writable, not yaml
+			return []string{"/dev/null/app.yaml", "app.json"}
+		},
+		FindInAppNameSubdir,
+		FindInCwd,
+	}
+
+	file, err := WriteConfigFile(cfg, &cobra.Command{}, "", false, &config{})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(".app", "config.yaml"), file)
+	require.FileExists(t, file)
+
+	// the existing file is found, but not replaced
+	_, err = WriteConfigFile(cfg, &cobra.Command{}, "", false, &config{})
+	require.ErrorContains(t, err, "configuration file already exists: "+filepath.Join(".app", "config.yaml"))
+
+	cfg.Finders = []Finder{
+		func(_ Config) []string {
+			return []string{"/dev/null/app.yaml"}
+		},
+	}
+	_, err = WriteConfigFile(cfg, &cobra.Command{}, "", false, &config{})
+	require.ErrorContains(t, err, "unable to find a writable configuration file location")
+}
+
+func Test_WriteConfigFileUsesLookupEnv(t *testing.T) {
+	home := t.TempDir()
+
+	type config struct {
+		Name string `mapstructure:"name"`
+	}
+
+	cfg := NewConfig("app")
+	cfg.LookupEnv = func(key string) (string, bool) {
+		if key == "HOME" {
+			return home, true
+		}
+		return "", false
+	}
+
+	file, err := WriteConfigFile(cfg, &cobra.Command{}, "~/.app.yaml", false, &config{})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, ".app.yaml"), file)
+	require.FileExists(t, file)
+
+	// the home directory location found by the Finders is also expanded using LookupEnv
+	require.NoError(t, os.Remove(file))
+	cfg.Finders = []Finder{FindInHomeDir}
+	file, err = WriteConfigFile(cfg, &cobra.Command{}, "", false, &config{})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, ".app.yaml"), file)
+	require.FileExists(t, file)
+}
@@ -6,3 +6,9 @@ package fangs
 type PostLoader interface {
 	PostLoad() error
 }
+
+// Validator is the interface used to do any sort of custom validation after `config.Load` has been called.
+// This runs after PostLoad, and any error returned is reported along with all other validation failures
+type Validator interface {
+	Validate() error
+}
@@ -3,30 +3,75 @@ package fangs
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"strings"
 
-	"dario.cat/mergo"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-
-	"github.com/anchore/go-homedir"
 )
 
 func Load(cfg Config, cmd *cobra.Command, configurations ...any) error {
-	return loadConfig(cfg, commandFlagRefs(cmd), configurations...)
+	return loadConfig(cfg, commandFlagRefs(cmd), nil, configurations...)
 }
 
 func LoadAt(cfg Config, cmd *cobra.Command, path string, configuration any) error {
 	return Load(cfg, cmd, rootAt(cfg, configuration, path))
 }
 
-// loadConfig loads all configurations based on the provided settings, configuration variables are loaded
-// based on priority of:  viper.Set, flag, env, config, kv, defaults
-func loadConfig(cfg Config, flags flagRefs, configurations ...any) error {
+// LoadFlags loads configurations the same as Load, for applications using a pflag.FlagSet without cobra. flags may be
+// nil, in which case configuration is loaded from files and environment variables only
+func LoadFlags(cfg Config, flags *pflag.FlagSet, configurations ...any) error {
+	return LoadFlagSets(cfg, []*pflag.FlagSet{flags}, configurations...)
+}
+
+// LoadFlagsAt loads configuration the same as LoadAt, for applications using a pflag.FlagSet without cobra
+func LoadFlagsAt(cfg Config, flags *pflag.FlagSet, path string, configuration any) error {
+	return LoadFlags(cfg, flags, rootAt(cfg, configuration, path))
+}
+
+// LoadFlagSets loads configurations the same as Load, using flags from all the flag sets; nil flag sets are ignored
+func LoadFlagSets(cfg Config, flagSets []*pflag.FlagSet, configurations ...any) error {
+	return loadConfig(cfg, getFlagRefs(flagSets...), nil, configurations...)
+}
+
+// LoadInto loads configuration into a copy of the defaults, returning the loaded copy; the defaults are not modified,
+// so the same defaults may be loaded repeatedly. Flags bound to fields of the defaults set the corresponding fields
+// of the copy. A nil defaults is loaded into a new, zero value
+func LoadInto[T any](cfg Config, cmd *cobra.Command, defaults *T) (*T, error) {
+	return loadInto(cfg, cmd, defaults, func(configuration *T) any {
+		return configuration
+	})
+}
+
+// LoadIntoAt loads configuration into a copy of the defaults the same as LoadInto, with the configuration rooted at
+// the given path as with LoadAt
+func LoadIntoAt[T any](cfg Config, cmd *cobra.Command, path string, defaults *T) (*T, error) {
+	return loadInto(cfg, cmd, defaults, func(configuration *T) any {
+		return rootAt(cfg, configuration, path)
+	})
+}
+
+func loadInto[T any](cfg Config, cmd *cobra.Command, defaults *T, root func(*T) any) (*T, error) {
+	if defaults == nil {
+		defaults = new(T)
+	}
+	configuration, addresses := deepCopy(defaults)
+	flags := rebaseFlagRefs(commandFlagRefs(cmd), addresses)
+	if err := loadConfig(cfg, flags, nil, root(configuration)); err != nil {
+		return nil, err
+	}
+	return configuration, nil
+}
+
+// loadConfig loads all configurations based on the provided settings, configuration values are merged from all
+// sources based on priority, by default: flags, env, config files, then the existing values as defaults. the origin of
+// each value is recorded to prov, if provided
+func loadConfig(cfg Config, flags flagRefs, prov Provenance, configurations ...any) error {
 	// ensure the config is set up sufficiently
 	if cfg.Logger == nil || cfg.Finders == nil {
 		return fmt.Errorf("config.Load requires logger and finders to be set, but only has %+v", cfg)
@@ -38,28 +83,61 @@ func loadConfig(cfg Config, flags flagRefs, configurations ...any) error {
 		}
 	}
 
-	files, err := findConfigurationFiles(cfg)
+	if prov == nil {
+		prov = Provenance{}
+	}
+
+	// all configurations need to be configured to know which paths may be set by environment variables and flags
+	var paths []configPath
+	known := set[string]{}
+	for _, configuration := range configurations {
+		configurePaths(cfg, nil, set[reflect.Value]{}, reflect.ValueOf(configuration), flags, &paths, known, []string{})
+	}
+
+	merge, err := newMergeConfig(cfg, paths)
 	if err != nil {
 		return err
 	}
 
-	v, err := readConfigurationFiles(cfg, files)
+	files := &fileSource{cfg: cfg, merge: merge}
+	sources := append([]Source{
+		files,
+		&envSource{cfg: cfg, paths: paths},
+		&flagSource{paths: paths},
+		&overrideSource{cfg: cfg, paths: paths},
+	}, cfg.Sources...)
+
+	values, err := readSources(sources, prov, merge)
 	if err != nil {
 		return err
 	}
 
-	err = mergeProfiles(cfg, v)
+	for key := range known {
+		if !prov.covers(key) {
+			prov.set(key, Origin{Kind: SourceDefault})
+		}
+	}
+
+	if cfg.Strict {
+		err = checkUnknownKeys(cfg, prov, known)
+		if err != nil {
+			return err
+		}
+	}
+
+	v := viper.New()
+	err = v.MergeConfigMap(values)
 	if err != nil {
 		return err
 	}
 
-	// loading configurations now will have a merged set of configuration files with the following behavior:
-	// each configuration file is loaded, in priority order where the first takes precedence if the same key
-	// is defined in multiple files. lists and map configurations will have values appended, and profiles
-	// will overwrite values
-	for _, configuration := range configurations {
-		configureViper(cfg, v, nil, set[reflect.Value]{}, reflect.ValueOf(configuration), flags, []string{})
+	// we had been setting config previously to a string, so keep this behavior for now;
+	// viper seems to magically split this string if the target is a []string
+	v.Set("config", strings.Join(files.files, ","))
 
+	var loadIssues []LoadIssue
+	var validationIssues []ValidationIssue
+	for _, configuration := range configurations {
 		// unmarshal fully populated viper object onto config
 		err := unmarshalRecover(v, configuration, func(dc *mapstructure.DecoderConfig) {
 			dc.TagName = cfg.TagName
@@ -67,7 +145,14 @@ func loadConfig(cfg Config, flags flagRefs, configurations ...any) error {
 			dc.ZeroFields = true
 		})
 		if err != nil {
-			return err
+			err = newLoadError(cfg, prov, merge, err)
+			var loadErr *LoadError
+			if !errors.As(err, &loadErr) {
+				return err
+			}
+			// collect decode issues from all configurations, so they can be fixed at once
+			loadIssues = append(loadIssues, loadErr.Issues...)
+			continue
 		}
 
 		// Convert all populated config options to their internal application values ex: scope string => scopeOpt source.Scope
@@ -75,6 +160,17 @@ func loadConfig(cfg Config, flags flagRefs, configurations ...any) error {
 		if err != nil {
 			return err
 		}
+
+		// validate after all values are loaded, collecting all issues from all configurations to report at once
+		validationIssues = append(validationIssues, validate(cfg, flags, reflect.ValueOf(configuration))...)
+	}
+
+	if len(loadIssues) > 0 {
+		return &LoadError{Issues: loadIssues}
+	}
+
+	if len(validationIssues) > 0 {
+		return &ValidationError{Issues: validationIssues}
 	}
 
 	return nil
@@ -102,11 +198,12 @@ func unmarshalRecover(v *viper.Viper, target any, opts ...viper.DecoderConfigOpt
 func findConfigurationFiles(cfg Config) (files []string, err error) {
 	// load all explicitly configured files specified in cfg.Files and verify they exist
 	for _, f := range Flatten(cfg.Files...) {
-		f, err = homedir.Expand(f)
+		f, err = expandHome(cfg, f)
 		if err != nil {
 			return nil, fmt.Errorf("unable to expand path: %s", f)
 		}
-		if !fileExists(f) {
+		f = workDirPath(cfg, f)
+		if !fileExistsFS(configFS(cfg), f) {
 			return nil, fmt.Errorf("file does not exist: %v", f)
 		}
 		files = append(files, f)
@@ -120,11 +217,21 @@ func findConfigurationFiles(cfg Config) (files []string, err error) {
 		return files, nil
 	}
 
+	// finders may overlap, such as when searching the working directory and its parents
+	found := set[string]{}
 	for _, finder := range cfg.Finders {
 		for _, file := range finder(cfg) {
-			if !fileExists(file) {
+			if !fileExistsFS(configFS(cfg), file) {
+				continue
+			}
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				abs = file
+			}
+			if found.contains(abs) {
 				continue
 			}
+			found.add(abs)
 			files = append(files, file)
 			if !cfg.MultiFile {
 				// if not allowing implicit config inheritance, just return the first file
@@ -136,101 +243,127 @@ func findConfigurationFiles(cfg Config) (files []string, err error) {
 	return files, nil
 }
 
-// readConfigurationFiles reads all configurations, appending slice values
-func readConfigurationFiles(cfg Config, files []string) (v *viper.Viper, err error) {
-	v = newViper(cfg)
-
+// readConfigurationFiles reads all configurations, merging slice values based on the merge strategies. files included by a configuration file
+// are read directly after it, so have lower priority than the including file
+func readConfigurationFiles(cfg Config, files []string, prov Provenance, merge mergeConfig) (*viper.Viper, error) {
+	r := newConfigReader(cfg, prov)
+	r.merge = merge
 	for _, f := range files {
-		newV := newViper(cfg)
-
-		newV.SetConfigFile(f)
-		err = newV.ReadInConfig()
-		if err != nil {
-			if isNotFoundErr(err) {
-				cfg.Logger.Debug("no config file found, using defaults")
-			} else {
-				return nil, fmt.Errorf("unable to load config: %w", err)
-			}
-		}
-
-		all := v.AllSettings()
-		incoming := newV.AllSettings()
-
-		// merge configuration slices in priority order, so slices will have high priority entries first, and retain
-		// existing entries instead of overwriting them
-		err = mergo.Merge(&all, incoming, mergo.WithAppendSlice)
-		if err != nil {
-			return nil, err
-		}
-
-		// viper merge will overwrite same keys, we have appended slices to the previous config in the previous step
-		err = v.MergeConfigMap(all)
-		if err != nil {
+		if err := r.readFile(f, nil); err != nil {
 			return nil, err
 		}
 	}
 
-	// we had been setting config previously to a string, so keep this behavior for now;
-	// viper seems to magically split this string if the target is a []string
-	v.Set("config", strings.Join(files, ","))
-
-	return v, nil
-}
-
-// newViper returns a configured new viper instance
-func newViper(cfg Config) *viper.Viper {
-	// EnvKeyReplacer allows for nested options to be specified via environment variables
-	// e.g. pod.context = APPNAME_POD_CONTEXT
-	var v = viper.NewWithOptions(viper.EnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_")))
-
-	// load environment variables
-	v.SetEnvPrefix(cfg.AppName)
-	v.AllowEmptyEnv(true)
-	v.AutomaticEnv()
-	return v
+	return r.v, nil
 }
 
 // mergeProfiles merges profile sections in the viper config map to appropriate locations in the top-level configuration
-func mergeProfiles(cfg Config, v *viper.Viper) error {
-	if len(cfg.Profiles) == 0 {
+func mergeProfiles(cfg Config, v *viper.Viper, prov Provenance, merge mergeConfig) error {
+	all := v.AllSettings()
+	selected, err := selectProfiles(cfg, all)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
 		return nil // no profiles requested
 	}
 	if cfg.ProfileKey == "" {
 		return fmt.Errorf("invalid configuration: fangs.Config.ProfileKey not defined")
 	}
 	// merge all profiles in to main configuration locations, overwriting
-	all := v.AllSettings()
 	profiles, ok := all[cfg.ProfileKey].(map[string]any)
 	if !ok || profiles == nil {
 		return fmt.Errorf("'%v' not found in any configuration files", cfg.ProfileKey)
 	}
-	for _, profileName := range Flatten(cfg.Profiles...) {
-		profileVals, ok := profiles[profileName].(map[string]any)
-		if !ok || profileVals == nil {
-			// profile not defined, consider this an error as the user explicitly requested it and probably mistyped
-			return fmt.Errorf("profile not found in any configuration files: %v", profileName)
-		}
-		// overwrite same keys -- this is what we want for profile selection, the profiles will already have
-		// appended values if the same profile was found in multiple config files
-		err := mergo.Merge(&all, profileVals, mergo.WithOverride, mergo.WithOverwriteWithEmptyValue)
+	for _, requested := range selected {
+		names, err := resolveProfile(cfg, profiles, requested, nil, set[string]{})
 		if err != nil {
 			return err
 		}
-		// merge the incoming config, this should replace anything in the existing config with the new values
-		err = v.MergeConfigMap(all)
-		if err != nil {
-			return err
+		for _, profileName := range names {
+			profileVals := profileValues(cfg, profiles[profileName].(map[string]any))
+			for _, key := range flattenKeys(profileVals) {
+				file := prov[strings.ToLower(strings.Join([]string{cfg.ProfileKey, profileName, key}, "."))].File
+				prov.set(key, Origin{Kind: SourceProfile, File: file, Profile: profileName})
+			}
+			// overwrite same keys -- this is what we want for profile selection, the profiles will already have
+			// appended values if the same profile was found in multiple config files
+			mergeValues(all, profileVals, merge, MergeReplace)
+			// merge the incoming config, this should replace anything in the existing config with the new values
+			err := v.MergeConfigMap(all)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
-// configureViper loads the default configuration values into the viper instance,
-// before the config values are read and parsed. the value _must_ be a pointer but
-// may be a pointer to a pointer
-//
+// resolveProfile returns the names of the profiles to apply for the profile, in order: the profiles it extends,
+// recursively, followed by the profile itself. extending is the chain of profiles extending this profile, used to
+// detect cycles, and resolved profiles are only applied once
+func resolveProfile(cfg Config, profiles map[string]any, name string, extending []string, resolved set[string]) ([]string, error) {
+	if slices.Contains(extending, name) {
+		return nil, fmt.Errorf("profiles extend each other: %s", strings.Join(append(extending, name), " -> "))
+	}
+	profileVals, ok := profiles[name].(map[string]any)
+	if !ok || profileVals == nil {
+		// profile not defined, consider this an error as the user explicitly requested it and probably mistyped
+		if len(extending) > 0 {
+			return nil, fmt.Errorf("profile not found in any configuration files: %v, extended by: %v", name, extending[len(extending)-1])
+		}
+		return nil, fmt.Errorf("profile not found in any configuration files: %v, available profiles: %v", name, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+	if resolved.contains(name) {
+		return nil, nil
+	}
+
+	parents, err := profileExtends(cfg, name, profileVals)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, parent := range parents {
+		names, err := resolveProfile(cfg, profiles, parent, append(extending, name), resolved)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, names...)
+	}
+	resolved.add(name)
+	return append(out, name), nil
+}
+
+// profileExtends returns the names of the profiles the profile extends, from the ProfileExtendsKey value, which may be
+// a single name or a list of names
+func profileExtends(cfg Config, name string, profileVals map[string]any) ([]string, error) {
+	if cfg.ProfileExtendsKey == "" {
+		return nil, nil
+	}
+	value := profileVals[strings.ToLower(cfg.ProfileExtendsKey)]
+	names, ok := profileNames(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' in profile %s, expected a profile name or list of names: %v", cfg.ProfileExtendsKey, name, value)
+	}
+	return names, nil
+}
+
+// profileValues returns the values the profile sets, excluding the ProfileExtendsKey
+func profileValues(cfg Config, profileVals map[string]any) map[string]any {
+	key := strings.ToLower(cfg.ProfileExtendsKey)
+	if _, ok := profileVals[key]; !ok || key == "" {
+		return profileVals
+	}
+	out := maps.Clone(profileVals)
+	delete(out, key)
+	return out
+}
 
-func configureViper(cfg Config, vpr *viper.Viper, configuring []reflect.Type, visited set[reflect.Value], v reflect.Value, flags flagRefs, path []string) {
+// configurePaths collects the paths of all values in the configuration, along with the environment variable and flag
+// which may be used to set each. nil struct pointers are initialized so their values may be loaded. the value _must_
+// be a pointer but may be a pointer to a pointer
+func configurePaths(cfg Config, configuring []reflect.Type, visited set[reflect.Value], v reflect.Value, flags flagRefs, paths *[]configPath, known set[string], path []string) {
 	if visited.contains(v) {
 		return
 	}
@@ -238,7 +371,7 @@ func configureViper(cfg Config, vpr *viper.Viper, configuring []reflect.Type, vi
 
 	t := v.Type()
 	if !isPtr(t) {
-		panic(fmt.Sprintf("configureViper v must be a pointer, got: %#v", v))
+		panic(fmt.Sprintf("configurePaths v must be a pointer, got: %#v", v))
 	}
 
 	// v is always a pointer
@@ -253,49 +386,28 @@ func configureViper(cfg Config, vpr *viper.Viper, configuring []reflect.Type, vi
 	}
 
 	if !isStruct(t) {
-		envVar := envVar(cfg.AppName, path...)
-		path := strings.Join(path, ".")
-
-		if flag, ok := flags[ptr]; ok {
-			cfg.Logger.Tracef("binding env var w/flag: %s", envVar)
-			err := vpr.BindPFlag(path, flag)
-			if err != nil {
-				cfg.Logger.Debugf("unable to bind flag: %s to %#v", path, flag)
-			}
-			return
-		}
-
-		cfg.Logger.Tracef("binding env var: %s", envVar)
-
-		vpr.SetDefault(path, nil) // no default value actually needs to be set for Viper to read config values
+		known.add(strings.ToLower(strings.Join(path, ".")))
+		*paths = append(*paths, configPath{
+			path:   slices.Clone(path),
+			envVar: envVar(cfg.AppName, path...),
+			flag:   flags[ptr],
+			typ:    t,
+		})
 		return
 	}
 
 	// for each field in the configuration struct, see if the field implements the defaultValueLoader interface and invoke it if it does
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
-		if !includeField(f) {
+		key, squash, ok := fieldKey(cfg, f)
+		if !ok {
 			continue
 		}
 
 		path := path
-		if tag, ok := f.Tag.Lookup(cfg.TagName); ok {
-			// handle ,squash mapstructure tags
-			parts := strings.Split(tag, ",")
-			tag = parts[0]
-			if tag == "-" {
-				continue
-			}
-			switch {
-			case contains(parts, "squash"):
-				// use the current path
-			case tag == "":
-				path = append(path, f.Name)
-			default:
-				path = append(path, tag)
-			}
-		} else {
-			path = append(path, f.Name)
+		if !squash {
+			// squashed fields use the current path
+			path = append(path, key)
 		}
 
 		if !v.IsValid() {
@@ -326,7 +438,21 @@ func configureViper(cfg Config, vpr *viper.Viper, configuring []reflect.Type, vi
 			}
 		}
 
-		configureViper(cfg, vpr, fieldConfiguring, visited, v.Addr(), flags, path)
+		configured := len(*paths)
+		configurePaths(cfg, fieldConfiguring, visited, v.Addr(), flags, paths, known, path)
+
+		// a merge strategy applies to all values within the field, unless set on a nested field
+		if tag, ok := f.Tag.Lookup(mergeStrategyTag); ok {
+			strategy, key := parseMergeTag(tag)
+			for i := configured; i < len(*paths); i++ {
+				if (*paths)[i].merge == "" {
+					(*paths)[i].merge = strategy
+				}
+				if (*paths)[i].mergeKey == "" {
+					(*paths)[i].mergeKey = key
+				}
+			}
+		}
 	}
 }
 
@@ -456,12 +582,18 @@ func postLoadMap(v reflect.Value) error {
 type flagRefs map[uintptr]*pflag.Flag
 
 func commandFlagRefs(cmd *cobra.Command) flagRefs {
+	if cmd == nil {
+		return flagRefs{}
+	}
 	return getFlagRefs(cmd.PersistentFlags(), cmd.Flags())
 }
 
 func getFlagRefs(flagSets ...*pflag.FlagSet) flagRefs {
 	refs := flagRefs{}
 	for _, flags := range flagSets {
+		if flags == nil {
+			continue
+		}
 		flags.VisitAll(func(flag *pflag.Flag) {
 			refs[getFlagRef(flag)] = flag
 		})
@@ -523,12 +655,6 @@ func isNil(v reflect.Value) bool {
 	return false
 }
 
-// isNotFoundErr returns true if the error is a viper.ConfigFileNotFoundError
-func isNotFoundErr(err error) bool {
-	var notFound *viper.ConfigFileNotFoundError
-	return err != nil && errors.As(err, &notFound)
-}
-
 // includeField determines whether to include or skip a field when processing the application's nested configuration load.
 // fields that are processed include: public/exported fields, embedded structs (not pointer private/unexported embedding)
 func includeField(f reflect.StructField) bool {
@@ -555,6 +681,10 @@ func (s set[T]) add(v T) {
 	s[v] = struct{}{}
 }
 
+func (s set[T]) remove(v T) {
+	delete(s, v)
+}
+
 func (s set[T]) contains(v T) bool {
 	_, ok := s[v]
 	return ok
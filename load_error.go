@@ -0,0 +1,301 @@
+package fangs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// LoadError is returned when configuration values cannot be decoded, listing all values that failed to decode
+type LoadError struct {
+	Issues []LoadIssue
+}
+
+// LoadIssue describes a single configuration value that could not be decoded
+type LoadIssue struct {
+	// Path is the configuration path of the value, e.g. scanning.depth or exclude[2]
+	Path string
+
+	// File is the configuration file the value was read from, if read from a file
+	File string
+
+	// Line is the line number of the value within the File, if known
+	Line int
+
+	// Column is the column number of the value within the File, if known
+	Column int
+
+	// Profile is the name of the profile the value was read from, if read from a profile
+	Profile string
+
+	// EnvVar is the environment variable the value was read from, if read from an environment variable
+	EnvVar string
+
+	// Flag is the flag the value was read from, if read from a flag
+	Flag string
+
+	// Expected is the Go type the value was expected to be, if known
+	Expected string
+
+	// Err is the underlying decode error
+	Err error
+}
+
+func (e *LoadError) Error() string {
+	lines := []string{"unable to load configuration:"}
+	for _, issue := range e.Issues {
+		lines = append(lines, "  "+issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (i LoadIssue) String() string {
+	location := ""
+	switch {
+	case i.File != "":
+		location = i.File
+		if i.Line > 0 {
+			location += fmt.Sprintf(":%d:%d", i.Line, i.Column)
+		}
+		if i.Profile != "" {
+			location += fmt.Sprintf(" (profile: %s)", i.Profile)
+		}
+	case i.EnvVar != "":
+		location = "env " + i.EnvVar
+	case i.Flag != "":
+		location = "flag --" + i.Flag
+	}
+	out := fmt.Sprintf("'%s' %v", i.Path, i.Err)
+	if location != "" {
+		out = location + ": " + out
+	}
+	return out
+}
+
+// newLoadError converts mapstructure decode errors into a LoadError, with the location of each value from the
+// provenance and configuration files; other errors are returned unchanged
+func newLoadError(cfg Config, prov Provenance, merge mergeConfig, err error) error {
+	decodeErrs := decodeErrors(err)
+	if len(decodeErrs) == 0 {
+		return err
+	}
+
+	docs := map[string]*yaml.Node{}
+	var issues []LoadIssue
+	for _, decodeErr := range decodeErrs {
+		issue := LoadIssue{
+			Path: decodeErr.Name(),
+			Err:  decodeErr.Unwrap(),
+		}
+
+		var parseErr *mapstructure.ParseError
+		var typeErr *mapstructure.UnconvertibleTypeError
+		switch {
+		case errors.As(decodeErr, &parseErr):
+			issue.Expected = parseErr.Expected.Type().String()
+		case errors.As(decodeErr, &typeErr):
+			issue.Expected = typeErr.Expected.Type().String()
+		}
+
+		segments := pathSegments(issue.Path)
+		if origin, ok := prov.Lookup(provenanceKey(segments)); ok {
+			issue.File = origin.File
+			issue.Profile = origin.Profile
+			issue.EnvVar = origin.EnvVar
+			issue.Flag = origin.Flag
+
+			if issue.File != "" {
+				fileSegments := segments
+				if origin.Kind == SourceProfile {
+					fileSegments = append([]string{cfg.ProfileKey, origin.Profile}, segments...)
+				}
+				line, column, found := findPosition(configFS(cfg), docs, issue.File, fileSegments)
+				if found && listIndexesInFile(merge, segments) {
+					issue.Line, issue.Column = line, column
+				} else {
+					// the list entry was merged from another source, so it is not known where the value is from
+					issue.File, issue.Profile = "", ""
+				}
+			}
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return &LoadError{Issues: issues}
+}
+
+// decodeErrors returns all the individual field errors within a mapstructure error
+func decodeErrors(err error) (out []*mapstructure.DecodeError) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			out = append(out, decodeErrors(e)...)
+		}
+		return out
+	}
+
+	decodeErr, ok := err.(*mapstructure.DecodeError)
+	if !ok {
+		if wrapped := errors.Unwrap(err); wrapped != nil {
+			return decodeErrors(wrapped)
+		}
+		return nil
+	}
+
+	// a field error may contain errors for nested fields
+	if nested := decodeErrors(decodeErr.Unwrap()); len(nested) > 0 {
+		return nested
+	}
+	return []*mapstructure.DecodeError{decodeErr}
+}
+
+// pathSegments splits a mapstructure field name such as sub.items[0].labels[some.key] into segments:
+// sub, items, [0], labels, [some.key]
+func pathSegments(name string) (out []string) {
+	current := strings.Builder{}
+	flush := func() {
+		if current.Len() > 0 {
+			out = append(out, current.String())
+			current.Reset()
+		}
+	}
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '.':
+			flush()
+		case '[':
+			flush()
+			end := strings.IndexByte(name[i:], ']')
+			if end < 0 {
+				end = len(name) - i - 1
+			}
+			out = append(out, name[i:i+end+1])
+			i += end
+		default:
+			current.WriteByte(name[i])
+		}
+	}
+	flush()
+	return out
+}
+
+// provenanceKey returns the provenance key for path segments, which does not include slice indexes
+func provenanceKey(segments []string) string {
+	var keys []string
+	for _, segment := range segments {
+		if isIndexSegment(segment) {
+			if _, err := strconv.Atoi(segment[1 : len(segment)-1]); err == nil {
+				break
+			}
+			segment = segment[1 : len(segment)-1]
+		}
+		keys = append(keys, segment)
+	}
+	return strings.Join(keys, ".")
+}
+
+func isIndexSegment(segment string) bool {
+	return strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]")
+}
+
+// listIndexesInFile returns true if list indexes in the path segments are the same as the indexes in the file the
+// list is attributed to, which is the highest priority file defining the list: this is the case when its entries are
+// first in the merged list and are not merged with entries from other files by key
+func listIndexesInFile(merge mergeConfig, segments []string) bool {
+	var path []string
+	for _, segment := range segments {
+		if !isIndexSegment(segment) {
+			path = append(path, strings.ToLower(segment))
+			continue
+		}
+		if _, err := strconv.Atoi(segment[1 : len(segment)-1]); err != nil {
+			// a map key
+			path = append(path, strings.ToLower(segment[1:len(segment)-1]))
+			continue
+		}
+		listPath := strings.Join(path, ".")
+		strategy := merge.strategy(listPath, MergePrepend)
+		if merge.keys[listPath] != "" || (strategy != MergePrepend && strategy != MergeReplace) {
+			return false
+		}
+	}
+	return true
+}
+
+// findPosition returns the line and column of the deepest YAML node found for the path segments in the file, and
+// false if a list index is not within the list in the file. parsed documents are cached in docs
+func findPosition(fsys fs.FS, docs map[string]*yaml.Node, file string, segments []string) (line, column int, found bool) {
+	doc, ok := docs[file]
+	if !ok {
+		doc = parseYAML(fsys, file)
+		docs[file] = doc
+	}
+	if doc == nil {
+		return 0, 0, true
+	}
+
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, segment := range segments {
+		next := childNode(node, segment)
+		if next == nil {
+			if node.Kind == yaml.SequenceNode {
+				return 0, 0, false
+			}
+			break
+		}
+		node = next
+	}
+	return node.Line, node.Column, true
+}
+
+func parseYAML(fsys fs.FS, file string) *yaml.Node {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil
+	}
+	contents, err := readFileFS(fsys, file)
+	if err != nil {
+		return nil
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, doc); err != nil {
+		return nil
+	}
+	return doc
+}
+
+// childNode returns the value node for the segment: a case-insensitive mapping key or a sequence index
+func childNode(node *yaml.Node, segment string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if isIndexSegment(segment) {
+			segment = segment[1 : len(segment)-1]
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, segment) {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if !isIndexSegment(segment) {
+			return nil
+		}
+		idx, err := strconv.Atoi(segment[1 : len(segment)-1])
+		if err != nil || idx < 0 || idx >= len(node.Content) {
+			return nil
+		}
+		return node.Content[idx]
+	default:
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package fangs
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadError(t *testing.T) {
+	type item struct {
+		Name  string `mapstructure:"name"`
+		Count int    `mapstructure:"count"`
+	}
+	type sub struct {
+		Enabled bool `mapstructure:"enabled"`
+	}
+	type config struct {
+		Depth  int               `mapstructure:"depth"`
+		Ratio  float64           `mapstructure:"ratio"`
+		Sub    sub               `mapstructure:"sub"`
+		Items  []item            `mapstructure:"items"`
+		Labels map[string]string `mapstructure:"labels"`
+	}
+	type other struct {
+		Name int `mapstructure:"name"`
+	}
+
+	tests := []struct {
+		name     string
+		profiles []string
+		expected []string
+	}{
+		{
+			name: "all issues from all files and configurations",
+			expected: []string{
+				"test-fixtures/load-error/1.yaml:1:8: 'depth' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+				"env MY_APP_RATIO: 'ratio' cannot parse value as 'float64': strconv.ParseFloat: invalid syntax",
+				"test-fixtures/load-error/1.yaml:4:12: 'sub.enabled' cannot parse value as 'bool': strconv.ParseBool: invalid syntax",
+				"test-fixtures/load-error/1.yaml:8:12: 'items[0].count' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+				"test-fixtures/load-error/2.yaml:4:5: 'labels[some-label]' expected type 'string', got unconvertible type 'map[string]interface {}'",
+				"test-fixtures/load-error/2.yaml:1:7: 'name' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+			},
+		},
+		{
+			name:     "profile values",
+			profiles: []string{"broken"},
+			expected: []string{
+				"test-fixtures/load-error/1.yaml:1:8: 'depth' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+				"env MY_APP_RATIO: 'ratio' cannot parse value as 'float64': strconv.ParseFloat: invalid syntax",
+				"test-fixtures/load-error/1.yaml:13:16 (profile: broken): 'sub.enabled' cannot parse value as 'bool': strconv.ParseBool: invalid syntax",
+				"test-fixtures/load-error/1.yaml:8:12: 'items[0].count' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+				"test-fixtures/load-error/2.yaml:4:5: 'labels[some-label]' expected type 'string', got unconvertible type 'map[string]interface {}'",
+				"test-fixtures/load-error/2.yaml:1:7: 'name' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("MY_APP_RATIO", "half")
+
+			cfg := NewConfig("my-app")
+			cfg.Files = []string{"test-fixtures/load-error/1.yaml", "test-fixtures/load-error/2.yaml"}
+			cfg.Profiles = test.profiles
+
+			err := Load(cfg, &cobra.Command{}, &config{}, &other{})
+
+			var loadErr *LoadError
+			require.ErrorAs(t, err, &loadErr)
+
+			var got []string
+			for _, issue := range loadErr.Issues {
+				got = append(got, issue.String())
+			}
+			assert.Equal(t, test.expected, got)
+
+			assert.Equal(t, "int", loadErr.Issues[0].Expected)
+			assert.Equal(t, 1, loadErr.Issues[0].Line)
+		})
+	}
+}
+
+func Test_pathSegments(t *testing.T) {
+	assert.Equal(t, []string{"sub", "items", "[0]", "labels", "[some.key]"}, pathSegments("sub.items[0].labels[some.key]"))
+	assert.Equal(t, "sub.items", provenanceKey(pathSegments("sub.items[0].name")))
+	assert.Equal(t, "labels.some-key", provenanceKey(pathSegments("labels[some-key]")))
+}
+
+func Test_LoadErrorMergedLists(t *testing.T) {
+	type item struct {
+		Name  string `mapstructure:"name"`
+		Count int    `mapstructure:"count"`
+	}
+	type config struct {
+		Items []item `mapstructure:"items"`
+	}
+
+	tests := []struct {
+		name       string
+		strategies map[string]MergeStrategy
+		expected   string
+	}{
+		{
+			name: "entry after the entries of the attributed file",
+			// the entry from 2.yaml is after the entry from 1.yaml
+			expected: "'items[1].count' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+		},
+		{
+			name:       "entry index within the attributed file",
+			strategies: map[string]MergeStrategy{"items": MergeAppend},
+			// the entry from 2.yaml is first, but 1.yaml also has an entry at index 0
+			expected: "'items[0].count' cannot parse value as 'int': strconv.ParseInt: invalid syntax",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := NewConfig("my-app")
+			cfg.Files = []string{"test-fixtures/load-error-lists/1.yaml", "test-fixtures/load-error-lists/2.yaml"}
+			cfg.MergeStrategies = test.strategies
+
+			err := Load(cfg, &cobra.Command{}, &config{})
+
+			var loadErr *LoadError
+			require.ErrorAs(t, err, &loadErr)
+			require.Len(t, loadErr.Issues, 1)
+			assert.Equal(t, test.expected, loadErr.Issues[0].String())
+			assert.Empty(t, loadErr.Issues[0].File)
+			assert.Zero(t, loadErr.Issues[0].Line)
+		})
+	}
+}
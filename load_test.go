@@ -9,6 +9,7 @@ import (
 
 	"github.com/adrg/xdg"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -260,6 +261,31 @@ func Test_LoadFromFlagsOverridingAll(t *testing.T) {
 	require.Equal(t, "flag-value-v", r.V)
 }
 
+func Test_LoadFromMapFlags(t *testing.T) {
+	type config struct {
+		Labels map[string]string `mapstructure:"labels"`
+		Limits map[string]int    `mapstructure:"limits"`
+	}
+
+	c := &config{
+		Labels: map[string]string{"default": "label"},
+		Limits: map[string]int{"default": 1},
+	}
+
+	cmd := &cobra.Command{}
+	flags := cmd.Flags()
+	flags.StringToStringVarP(&c.Labels, "labels", "", c.Labels, "labels usage")
+	flags.StringToIntVarP(&c.Limits, "limits", "", c.Limits, "limits usage")
+	require.NoError(t, flags.Parse([]string{"--labels", "a=1,b=x=y", "--limits", "cpu=2,mem=512"}))
+
+	require.NoError(t, Load(NewConfig("my-app"), cmd, c))
+
+	require.Equal(t, &config{
+		Labels: map[string]string{"a": "1", "b": "x=y"},
+		Limits: map[string]int{"cpu": 2, "mem": 512},
+	}, c)
+}
+
 func setup(_ *testing.T) (*cobra.Command, Config, *root, *sub) {
 	cfg := NewConfig("my-app")
 
@@ -1127,3 +1153,183 @@ func restoreCache(t testing.TB) {
 		homedir.Reset()
 	})
 }
+
+func Test_LoadInto(t *testing.T) {
+	cmd, cfg, r, _ := setup(t)
+	cfg.Files = []string{"test-fixtures/config.yaml"}
+	t.Setenv("MY_APP_SUB_UNBOUND", "env-unbound")
+
+	loaded, err := LoadInto(cfg, cmd, r)
+	require.NoError(t, err)
+
+	require.Equal(t, "direct-config-v", loaded.V)
+	require.Equal(t, "direct-config-sub-v", loaded.Sub.Sv)
+	require.Equal(t, "env-unbound", loaded.Sub.Unbound)
+
+	// defaults are unchanged, so may be loaded again
+	require.Equal(t, "default-v", r.V)
+	require.Equal(t, "default-sv", r.Sub.Sv)
+	require.Equal(t, "default-unbound", r.Sub.Unbound)
+	require.NotSame(t, r.Sub, loaded.Sub)
+
+	again, err := LoadInto(cfg, cmd, r)
+	require.NoError(t, err)
+	require.Equal(t, loaded, again)
+
+	// flags bound to the defaults set values in the copy
+	require.NoError(t, cmd.Flags().Set("sv", "flag-sv"))
+	loaded, err = LoadInto(cfg, cmd, r)
+	require.NoError(t, err)
+	require.Equal(t, "flag-sv", loaded.Sub.Sv)
+
+	t.Setenv("MY_APP_UNBOUND", "root-unbound")
+	empty, err := LoadInto[sub](cfg, cmd, nil)
+	require.NoError(t, err)
+	require.Equal(t, "root-unbound", empty.Unbound)
+}
+
+func Test_LoadIntoAt(t *testing.T) {
+	cmd, cfg, _, s := setup(t)
+	t.Setenv("MY_APP_NESTED_UNBOUND", "env-unbound")
+
+	loaded, err := LoadIntoAt(cfg, cmd, "nested", s)
+	require.NoError(t, err)
+
+	require.Equal(t, "env-unbound", loaded.Unbound)
+	require.Equal(t, "default-unbound", s.Unbound)
+}
+
+func Test_LoadFlags(t *testing.T) {
+	cfg := NewConfig("my-app")
+	cfg.Files = []string{"test-fixtures/config.yaml"}
+
+	r := &root{V: "default-v", Sub: &sub{Sv: "default-sv"}}
+	flags := pflag.NewFlagSet("my-app", pflag.ContinueOnError)
+	flags.StringVar(&r.V, "v", r.V, "v usage")
+	require.NoError(t, flags.Parse([]string{"--v", "flag-v"}))
+
+	require.NoError(t, LoadFlags(cfg, flags, r))
+	require.Equal(t, "flag-v", r.V)
+	require.Equal(t, "direct-config-sub-v", r.Sub.Sv)
+
+	s := &sub{}
+	require.NoError(t, LoadFlagsAt(cfg, flags, "sub", s))
+	require.Equal(t, "direct-config-sub-v", s.Sv)
+}
+
+func Test_LoadFlagSets(t *testing.T) {
+	cfg := NewConfig("my-app")
+
+	r := &root{Sub: &sub{}}
+	rootFlags := pflag.NewFlagSet("root", pflag.ContinueOnError)
+	rootFlags.StringVar(&r.V, "v", "", "v usage")
+	subFlags := pflag.NewFlagSet("sub", pflag.ContinueOnError)
+	subFlags.StringVar(&r.Sub.Sv, "sv", "", "sv usage")
+	require.NoError(t, rootFlags.Parse([]string{"--v", "flag-v"}))
+	require.NoError(t, subFlags.Parse([]string{"--sv", "flag-sv"}))
+	t.Setenv("MY_APP_V", "env-v")
+	t.Setenv("MY_APP_SUB_SV", "env-sv")
+
+	require.NoError(t, LoadFlagSets(cfg, []*pflag.FlagSet{rootFlags, nil, subFlags}, r))
+	require.Equal(t, "flag-v", r.V)
+	require.Equal(t, "flag-sv", r.Sub.Sv)
+}
+
+func Test_LoadWithoutFlags(t *testing.T) {
+	cfg := NewConfig("my-app")
+	t.Setenv("MY_APP_V", "env-v")
+
+	r := &root{V: "default-v", Sub: &sub{Sv: "default-sv"}}
+	require.NoError(t, LoadFlags(cfg, nil, r))
+	require.Equal(t, "env-v", r.V)
+	require.Equal(t, "default-sv", r.Sub.Sv)
+
+	r = &root{}
+	require.NoError(t, Load(cfg, nil, r))
+	require.Equal(t, "env-v", r.V)
+}
+
+func Test_ProfileExtends(t *testing.T) {
+	type config struct {
+		Name    string   `mapstructure:"name"`
+		Depth   int      `mapstructure:"depth"`
+		Verbose bool     `mapstructure:"verbose"`
+		FailOn  string   `mapstructure:"fail-on"`
+		Items   []string `mapstructure:"items"`
+	}
+
+	tests := []struct {
+		name     string
+		profiles []string
+		want     config
+		wantProv map[string]Origin
+		wantErr  string
+	}{
+		{
+			name:     "extends multiple profiles",
+			profiles: []string{"ci-strict"},
+			want:     config{Name: "default", Depth: 10, Verbose: true, FailOn: "low", Items: []string{"default"}},
+			wantProv: map[string]Origin{
+				"depth":   {Kind: SourceProfile, File: "test-fixtures/profile-extends/app.yaml", Profile: "ci-strict"},
+				"verbose": {Kind: SourceProfile, File: "test-fixtures/profile-extends/app.yaml", Profile: "ci"},
+				"fail-on": {Kind: SourceProfile, File: "test-fixtures/profile-extends/app.yaml", Profile: "strict"},
+			},
+		},
+		{
+			name:     "extends recursively",
+			profiles: []string{"nightly"},
+			want:     config{Name: "nightly", Depth: 10, Verbose: true, FailOn: "low", Items: []string{"default"}},
+		},
+		{
+			name:     "requested profiles override extended profiles",
+			profiles: []string{"ci-strict", "ci"},
+			want:     config{Name: "default", Depth: 5, Verbose: true, FailOn: "low", Items: []string{"default"}},
+		},
+		{
+			name:     "cycle",
+			profiles: []string{"loop-a"},
+			wantErr:  "profiles extend each other: loop-a -> loop-b -> loop-a",
+		},
+		{
+			name:     "extended profile not found",
+			profiles: []string{"broken"},
+			wantErr:  "profile not found in any configuration files: missing, extended by: broken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig("app")
+			cfg.ProfileExtendsKey = "extends"
+			cfg.Files = []string{"test-fixtures/profile-extends/app.yaml"}
+			cfg.Profiles = tt.profiles
+
+			c := &config{}
+			prov, err := LoadWithProvenance(cfg, &cobra.Command{}, c)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, *c)
+			for key, origin := range tt.wantProv {
+				require.Equal(t, origin, prov[key], key)
+			}
+		})
+	}
+}
+
+func Test_ProfileExtendsDisabled(t *testing.T) {
+	type config struct {
+		Depth   int    `mapstructure:"depth"`
+		Extends string `mapstructure:"extends"`
+	}
+
+	cfg := NewConfig("app")
+	cfg.Files = []string{"test-fixtures/profile-extends/app.yaml"}
+	cfg.Profiles = []string{"nightly"}
+
+	c := &config{}
+	require.NoError(t, Load(cfg, &cobra.Command{}, c))
+	require.Equal(t, config{Depth: 1, Extends: "ci-strict"}, *c)
+}
@@ -0,0 +1,228 @@
+package fangs
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// MergeStrategy determines how a list value set by multiple sources is merged, such as configuration files with
+// different priorities, profiles, environment variables and flags. A strategy is set for a field using a struct tag,
+// e.g. `merge:"replace"`, or for a configuration path using Config.MergeStrategies. Lists of structs may also be
+// merged by a key field, e.g. `merge:"append,key=name"` or using Config.MergeKeys, so entries from a higher priority
+// source update the entries from lower priority sources with the same key; the strategy then determines where other
+// entries are added. With MergeReplace, only the entries from the highest priority source are used, each merged with
+// the entry from lower priority sources with the same key, if any
+type MergeStrategy string
+
+const (
+	// MergeAppend adds values from a higher priority source after values from lower priority sources
+	MergeAppend MergeStrategy = "append"
+
+	// MergePrepend adds values from a higher priority source before values from lower priority sources; this is the
+	// default for configuration files
+	MergePrepend MergeStrategy = "prepend"
+
+	// MergeReplace uses only the values from the highest priority source; this is the default for profiles,
+	// environment variables and flags
+	MergeReplace MergeStrategy = "replace"
+
+	// MergeUnion adds values from a higher priority source after values from lower priority sources, omitting values
+	// already present
+	MergeUnion MergeStrategy = "union"
+)
+
+// mergeStrategyTag is the struct tag used to set the MergeStrategy and key for a field
+const mergeStrategyTag = "merge"
+
+var mergeStrategies = []MergeStrategy{MergeAppend, MergePrepend, MergeReplace, MergeUnion}
+
+// mergeConfig holds the merge strategies and keys of lists, by lowercase configuration path
+type mergeConfig struct {
+	strategies map[string]MergeStrategy
+	keys       map[string]string
+}
+
+// newMergeConfig returns the merge strategies and keys set for the configurations, with Config.MergeStrategies and
+// Config.MergeKeys taking precedence over struct tags
+func newMergeConfig(cfg Config, paths []configPath) (mergeConfig, error) {
+	out := mergeConfig{
+		strategies: map[string]MergeStrategy{},
+		keys:       map[string]string{},
+	}
+	for _, p := range paths {
+		path := strings.ToLower(strings.Join(p.path, "."))
+		if p.merge != "" {
+			out.strategies[path] = p.merge
+		}
+		if p.mergeKey != "" {
+			out.keys[path] = strings.ToLower(p.mergeKey)
+		}
+	}
+	for path, strategy := range cfg.MergeStrategies {
+		out.strategies[strings.ToLower(path)] = strategy
+	}
+	for path, key := range cfg.MergeKeys {
+		out.keys[strings.ToLower(path)] = strings.ToLower(key)
+	}
+	for path, strategy := range out.strategies {
+		if !slices.Contains(mergeStrategies, strategy) {
+			return mergeConfig{}, fmt.Errorf("invalid merge strategy for '%s': %s, expected one of: %v", path, strategy, mergeStrategies)
+		}
+	}
+	return out, nil
+}
+
+// parseMergeTag returns the strategy and key from a merge struct tag, such as: append,key=name
+func parseMergeTag(tag string) (strategy MergeStrategy, key string) {
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if k, ok := strings.CutPrefix(part, "key="); ok {
+			key = k
+			continue
+		}
+		strategy = MergeStrategy(part)
+	}
+	return strategy, key
+}
+
+// strategy returns the strategy for the path, or the fallback if none is set
+func (m mergeConfig) strategy(path string, fallback MergeStrategy) MergeStrategy {
+	if strategy, ok := m.strategies[path]; ok {
+		return strategy
+	}
+	return fallback
+}
+
+// mergeValues merges src, from a higher priority source, into dst, with nested maps merged and other values from src
+// replacing those in dst, except lists which are merged based on the strategy for their path
+func mergeValues(dst, src map[string]any, m mergeConfig, fallback MergeStrategy, path ...string) {
+	for key, value := range src {
+		valuePath := append(path[:len(path):len(path)], key)
+		if srcMap, ok := value.(map[string]any); ok {
+			dstMap, ok := dst[key].(map[string]any)
+			if !ok {
+				dstMap = map[string]any{}
+				dst[key] = dstMap
+			}
+			mergeValues(dstMap, srcMap, m, fallback, valuePath...)
+			continue
+		}
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		dst[key] = m.mergeList(strings.Join(valuePath, "."), fallback, existing, value)
+	}
+}
+
+// mergeList merges a value from a higher priority source with the existing value from lower priority sources based on
+// the strategy for the path; values are replaced unless at least one is a list. Lists from environment variables are
+// comma-separated strings
+func (m mergeConfig) mergeList(path string, fallback MergeStrategy, existing, value any) any {
+	strategy := m.strategy(path, fallback)
+	key := m.keys[path]
+	if (strategy == MergeReplace && key == "") || (!isList(existing) && !isList(value)) {
+		return value
+	}
+	existingList, existingOk := asList(existing)
+	list, ok := asList(value)
+	if !ok || !existingOk {
+		return value
+	}
+
+	if key != "" {
+		// entries with the same key are merged in place, other entries are added based on the strategy; when replacing,
+		// entries only in lower priority sources are dropped
+		var added, replaced []any
+		for _, entry := range list {
+			i := indexOfKey(existingList, key, entry)
+			if i < 0 {
+				added = append(added, entry)
+				replaced = append(replaced, entry)
+				continue
+			}
+			merged := maps.Clone(existingList[i].(map[string]any))
+			mergeValues(merged, entry.(map[string]any), m, fallback, path)
+			existingList[i] = merged
+			replaced = append(replaced, merged)
+		}
+		if strategy == MergeReplace {
+			return replaced
+		}
+		list = added
+	}
+
+	switch strategy {
+	case MergeAppend:
+		return append(existingList, list...)
+	case MergeUnion:
+		out := existingList
+		for _, v := range list {
+			if !containsValue(out, v) {
+				out = append(out, v)
+			}
+		}
+		return out
+	default:
+		return append(list, existingList...)
+	}
+}
+
+// indexOfKey returns the index of the map in values with the same key value as entry, or -1 if not found or entry
+// has no value for the key
+func indexOfKey(values []any, key string, entry any) int {
+	entryMap, ok := entry.(map[string]any)
+	if !ok {
+		return -1
+	}
+	id, ok := entryMap[key]
+	if !ok {
+		return -1
+	}
+	for i, v := range values {
+		if m, ok := v.(map[string]any); ok && reflect.DeepEqual(m[key], id) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isList(value any) bool {
+	v := reflect.ValueOf(value)
+	return v.IsValid() && v.Kind() == reflect.Slice
+}
+
+// asList returns a copy of the value as a list, for slice values and comma-separated strings
+func asList(value any) ([]any, bool) {
+	if s, ok := value.(string); ok {
+		out := []any{}
+		if s != "" {
+			for _, part := range strings.Split(s, ",") {
+				out = append(out, part)
+			}
+		}
+		return out, true
+	}
+	if !isList(value) {
+		return nil, false
+	}
+	v := reflect.ValueOf(value)
+	out := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		out = append(out, v.Index(i).Interface())
+	}
+	return out, true
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package fangs
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mergeList(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		existing any
+		value    any
+		want     any
+	}{
+		{
+			name:     "append",
+			strategy: MergeAppend,
+			existing: []any{"a", "b"},
+			value:    []any{"c"},
+			want:     []any{"a", "b", "c"},
+		},
+		{
+			name:     "prepend",
+			strategy: MergePrepend,
+			existing: []any{"a", "b"},
+			value:    []string{"c"},
+			want:     []any{"c", "a", "b"},
+		},
+		{
+			name:     "replace",
+			strategy: MergeReplace,
+			existing: []any{"a", "b"},
+			value:    []any{"c"},
+			want:     []any{"c"},
+		},
+		{
+			name:     "union",
+			strategy: MergeUnion,
+			existing: []any{"a", "b"},
+			value:    []any{"b", "c", "c"},
+			want:     []any{"a", "b", "c"},
+		},
+		{
+			name:     "append comma-separated string",
+			strategy: MergeAppend,
+			existing: []any{"a"},
+			value:    "b,c",
+			want:     []any{"a", "b", "c"},
+		},
+		{
+			name:     "scalars are replaced",
+			strategy: MergeAppend,
+			existing: "a",
+			value:    "b",
+			want:     "b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mergeConfig{strategies: map[string]MergeStrategy{"list": tt.strategy}}
+			require.Equal(t, tt.want, m.mergeList("list", MergePrepend, tt.existing, tt.value))
+		})
+	}
+}
+
+func Test_MergeStrategies(t *testing.T) {
+	type scanning struct {
+		Paths []string `mapstructure:"paths"`
+	}
+	type config struct {
+		Exclude  []string `mapstructure:"exclude" merge:"append"`
+		Output   []string `mapstructure:"output" merge:"replace"`
+		Tags     []string `mapstructure:"tags" merge:"union"`
+		Scanning scanning `mapstructure:"scanning" merge:"append"`
+	}
+
+	tests := []struct {
+		name       string
+		profiles   []string
+		env        map[string]string
+		strategies map[string]MergeStrategy
+		want       config
+	}{
+		{
+			name: "files",
+			want: config{
+				Exclude:  []string{"c", "a", "b"},
+				Output:   []string{"json"},
+				Tags:     []string{"y", "z", "x"},
+				Scanning: scanning{Paths: []string{"two", "one"}},
+			},
+		},
+		{
+			name:     "profiles",
+			profiles: []string{"extra"},
+			want: config{
+				Exclude:  []string{"c", "a", "b", "p"},
+				Output:   []string{"table"},
+				Tags:     []string{"y", "z", "x"},
+				Scanning: scanning{Paths: []string{"two", "one"}},
+			},
+		},
+		{
+			name: "env",
+			env:  map[string]string{"APP_EXCLUDE": "e", "APP_OUTPUT": "env", "APP_TAGS": "x,e"},
+			want: config{
+				Exclude:  []string{"c", "a", "b", "e"},
+				Output:   []string{"env"},
+				Tags:     []string{"y", "z", "x", "e"},
+				Scanning: scanning{Paths: []string{"two", "one"}},
+			},
+		},
+		{
+			name:       "config strategies replace tags",
+			strategies: map[string]MergeStrategy{"Scanning.Paths": MergePrepend, "output": MergeAppend},
+			want: config{
+				Exclude:  []string{"c", "a", "b"},
+				Output:   []string{"yaml", "json"},
+				Tags:     []string{"y", "z", "x"},
+				Scanning: scanning{Paths: []string{"one", "two"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig("app")
+			cfg.Files = []string{"test-fixtures/merge/1.yaml", "test-fixtures/merge/2.yaml"}
+			cfg.Profiles = tt.profiles
+			cfg.MergeStrategies = tt.strategies
+			cfg.LookupEnv = func(key string) (string, bool) {
+				value, ok := tt.env[key]
+				return value, ok
+			}
+
+			c := &config{}
+			require.NoError(t, Load(cfg, &cobra.Command{}, c))
+			require.Equal(t, tt.want, *c)
+		})
+	}
+}
+
+func Test_MergeStrategiesInvalid(t *testing.T) {
+	type config struct {
+		Exclude []string `mapstructure:"exclude" merge:"concat"`
+	}
+	err := Load(NewConfig("app"), &cobra.Command{}, &config{})
+	require.EqualError(t, err, "invalid merge strategy for 'exclude': concat, expected one of: [append prepend replace union]")
+}
+
+func Test_MergeKeys(t *testing.T) {
+	type scanner struct {
+		Name    string   `mapstructure:"name"`
+		Enabled bool     `mapstructure:"enabled"`
+		Level   string   `mapstructure:"level"`
+		Paths   []string `mapstructure:"paths"`
+	}
+	type taggedConfig struct {
+		Scanners []scanner `mapstructure:"scanners" merge:"append,key=name"`
+	}
+	type config struct {
+		Scanners []scanner `mapstructure:"scanners"`
+	}
+
+	merged := []scanner{
+		{Name: "packages", Enabled: true, Level: "low"},
+		{Name: "secrets", Enabled: false, Level: "low", Paths: []string{"src", "vendor"}},
+		{Enabled: true, Level: "unnamed"},
+		{Name: "licenses", Enabled: true},
+	}
+
+	t.Run("tag", func(t *testing.T) {
+		cfg := NewConfig("app")
+		cfg.Files = []string{"test-fixtures/merge-keys/1.yaml", "test-fixtures/merge-keys/2.yaml"}
+
+		c := &taggedConfig{}
+		require.NoError(t, Load(cfg, &cobra.Command{}, c))
+		require.Equal(t, merged, c.Scanners)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		cfg := NewConfig("app")
+		cfg.Files = []string{"test-fixtures/merge-keys/1.yaml", "test-fixtures/merge-keys/2.yaml"}
+		cfg.MergeKeys = map[string]string{"scanners": "Name"}
+		cfg.MergeStrategies = map[string]MergeStrategy{"scanners": MergeAppend, "scanners.paths": MergeReplace}
+
+		c := &config{}
+		require.NoError(t, Load(cfg, &cobra.Command{}, c))
+		require.Equal(t, []string{"src"}, c.Scanners[1].Paths)
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		cfg := NewConfig("app")
+		cfg.Files = []string{"test-fixtures/merge-keys/1.yaml", "test-fixtures/merge-keys/2.yaml"}
+		cfg.Profiles = []string{"strict"}
+
+		c := &taggedConfig{}
+		require.NoError(t, Load(cfg, &cobra.Command{}, c))
+		require.Equal(t, scanner{Name: "secrets", Enabled: false, Level: "high", Paths: []string{"src", "vendor"}}, c.Scanners[1])
+		require.Len(t, c.Scanners, 4)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		cfg := NewConfig("app")
+		cfg.Files = []string{"test-fixtures/merge-keys/1.yaml", "test-fixtures/merge-keys/2.yaml"}
+		cfg.MergeKeys = map[string]string{"scanners": "name"}
+		cfg.MergeStrategies = map[string]MergeStrategy{"scanners": MergeReplace}
+
+		// entries only in lower priority files are dropped, entries with the same key are still merged
+		c := &config{}
+		require.NoError(t, Load(cfg, &cobra.Command{}, c))
+		require.Equal(t, []scanner{
+			{Name: "secrets", Enabled: false, Level: "low", Paths: []string{"src", "vendor"}},
+			{Name: "licenses", Enabled: true},
+		}, c.Scanners)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		cfg := NewConfig("app")
+		cfg.Files = []string{"test-fixtures/merge-keys/1.yaml", "test-fixtures/merge-keys/2.yaml"}
+
+		c := &config{}
+		require.NoError(t, Load(cfg, &cobra.Command{}, c))
+		require.Len(t, c.Scanners, 5)
+	})
+}
+
+func Test_parseMergeTag(t *testing.T) {
+	strategy, key := parseMergeTag("append, key=name")
+	require.Equal(t, MergeAppend, strategy)
+	require.Equal(t, "name", key)
+
+	strategy, key = parseMergeTag("key=id")
+	require.Equal(t, MergeStrategy(""), strategy)
+	require.Equal(t, "id", key)
+}
@@ -0,0 +1,138 @@
+package fangs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// overrideSource reads values from the key.path=value assignments in Config.Overrides, parsing each value based on
+// the type of the configuration field it sets
+type overrideSource struct {
+	cfg   Config
+	paths []configPath
+	prov  Provenance
+}
+
+var _ interface {
+	Source
+	originProvider
+} = (*overrideSource)(nil)
+
+func (s *overrideSource) Name() string {
+	if s.cfg.SetFlag != "" {
+		return "--" + s.cfg.SetFlag
+	}
+	return "overrides"
+}
+
+func (s *overrideSource) Priority() int {
+	return OverridesPriority
+}
+
+func (s *overrideSource) Read() (map[string]any, error) {
+	s.prov = Provenance{}
+	out := map[string]any{}
+	for _, override := range s.cfg.Overrides {
+		key, value, ok := strings.Cut(override, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid assignment: '%s', expected key.path=value", override)
+		}
+
+		path := strings.Split(key, ".")
+		t, err := s.fieldType(path)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseOverride(t, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for '%s': %w", key, err)
+		}
+
+		setValue(out, path, parsed)
+		s.prov.set(key, s.origin())
+	}
+	return out, nil
+}
+
+func (s *overrideSource) origins() Provenance {
+	return s.prov
+}
+
+func (s *overrideSource) origin() Origin {
+	if s.cfg.SetFlag != "" {
+		return Origin{Kind: SourceFlag, Flag: s.cfg.SetFlag}
+	}
+	return Origin{Kind: SourceCustom, Source: s.Name()}
+}
+
+// fieldType returns the type of the configuration field at the path, which is either a configuration path or an
+// entry in a map at a configuration path. Entries nested deeper within a map return a nil type
+func (s *overrideSource) fieldType(path []string) (reflect.Type, error) {
+	key := strings.Join(path, ".")
+	var candidates []string
+	for _, p := range s.paths {
+		candidate := strings.ToLower(strings.Join(p.path, "."))
+		candidates = append(candidates, candidate)
+		if candidate == key {
+			return p.typ, nil
+		}
+		if p.typ != nil && p.typ.Kind() == reflect.Map && len(path) > len(p.path) && strings.HasPrefix(key, candidate+".") {
+			if len(path) == len(p.path)+1 {
+				return p.typ.Elem(), nil
+			}
+			return nil, nil
+		}
+	}
+
+	err := fmt.Sprintf("unknown configuration key: '%s'", key)
+	if suggestion := closestMatch(key, candidates); suggestion != "" {
+		err += fmt.Sprintf(", did you mean '%s'?", suggestion)
+	}
+	return nil, fmt.Errorf("%s", err)
+}
+
+// parseOverride parses the value for a field of the given type: lists and maps may be written as YAML flow
+// collections, such as [a, b] or {key: value}, lists may also be comma-separated; other values are decoded the same
+// as environment variables
+func parseOverride(t reflect.Type, value string) (any, error) {
+	for t != nil && isPtr(t) {
+		t = t.Elem()
+	}
+	if t == nil {
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		trimmed := strings.TrimSpace(value)
+		if !strings.HasPrefix(trimmed, "[") {
+			out := []string{}
+			if trimmed != "" {
+				for _, part := range strings.Split(value, ",") {
+					out = append(out, strings.TrimSpace(part))
+				}
+			}
+			return out, nil
+		}
+		var out []any
+		if err := yaml.Unmarshal([]byte(trimmed), &out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	case reflect.Map:
+		var out map[string]any
+		if err := yaml.Unmarshal([]byte(value), &out); err != nil {
+			return nil, err
+		}
+		if out == nil {
+			out = map[string]any{}
+		}
+		return out, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,116 @@
+package fangs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/go-logger/adapter/discard"
+)
+
+func Test_SetFlag(t *testing.T) {
+	type scanning struct {
+		Depth   int           `mapstructure:"depth"`
+		Timeout time.Duration `mapstructure:"timeout"`
+		Enabled *bool         `mapstructure:"enabled"`
+		Exclude []string      `mapstructure:"exclude"`
+		Ports   []int         `mapstructure:"ports"`
+	}
+	type config struct {
+		Name     string            `mapstructure:"name"`
+		Version  string            `mapstructure:"version"`
+		Scanning scanning          `mapstructure:"scanning"`
+		Labels   map[string]string `mapstructure:"labels"`
+		Limits   map[string]int    `mapstructure:"limits"`
+	}
+
+	t.Setenv("APP_SCANNING_DEPTH", "5")
+
+	c := &config{Name: "default"}
+
+	cfg := NewConfig("app")
+	cfg.SetFlag = "set"
+
+	cmd := &cobra.Command{}
+	cfg.AddFlags(NewPFlagSet(discard.New(), cmd.Flags()))
+	cmd.Flags().StringVar(&c.Name, "name", c.Name, "")
+	require.NoError(t, cmd.Flags().Parse([]string{
+		"--name", "flag",
+		"--set", "name=set",
+		"--set", "version=1.0",
+		"--set", "Scanning.Depth=10",
+		"--set", "scanning.timeout=5s",
+		"--set", "scanning.enabled=false",
+		"--set", "scanning.exclude=a, b",
+		"--set", "scanning.ports=[80, 443]",
+		"--set", "labels.owner=me",
+		"--set", "limits={cpu: 2, memory: 512}",
+	}))
+
+	prov, err := LoadWithProvenance(cfg, cmd, c)
+	require.NoError(t, err)
+
+	require.Equal(t, &config{
+		Name:    "set",
+		Version: "1.0",
+		Scanning: scanning{
+			Depth:   10,
+			Timeout: 5 * time.Second,
+			Enabled: p(false),
+			Exclude: []string{"a", "b"},
+			Ports:   []int{80, 443},
+		},
+		Labels: map[string]string{"owner": "me"},
+		Limits: map[string]int{"cpu": 2, "memory": 512},
+	}, c)
+
+	require.Equal(t, Origin{Kind: SourceFlag, Flag: "set"}, prov["scanning.depth"])
+	require.Equal(t, "flag --set", prov["labels.owner"].String())
+}
+
+func Test_SetFlagNotAdded(t *testing.T) {
+	cfg := NewConfig("app")
+	cmd := &cobra.Command{}
+	cfg.AddFlags(NewPFlagSet(discard.New(), cmd.Flags()))
+	require.Nil(t, cmd.Flags().Lookup("set"))
+}
+
+func Test_OverridesErrors(t *testing.T) {
+	type config struct {
+		Name   string         `mapstructure:"name"`
+		Limits map[string]int `mapstructure:"limits"`
+	}
+
+	tests := []struct {
+		override string
+		wantErr  string
+	}{
+		{
+			override: "name",
+			wantErr:  "invalid assignment: 'name', expected key.path=value",
+		},
+		{
+			override: "=value",
+			wantErr:  "invalid assignment: '=value', expected key.path=value",
+		},
+		{
+			override: "nmae=value",
+			wantErr:  "unknown configuration key: 'nmae', did you mean 'name'?",
+		},
+		{
+			override: "limits={cpu",
+			wantErr:  "invalid value for 'limits'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.override, func(t *testing.T) {
+			cfg := NewConfig("app")
+			cfg.Overrides = []string{tt.override}
+			err := Load(cfg, nil, &config{})
+			require.ErrorContains(t, err, tt.wantErr)
+			require.ErrorContains(t, err, "unable to read configuration from overrides")
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package fangs
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Profile describes a profile defined in configuration files
+type Profile struct {
+	// Name is the name of the profile, as used with the --profile flag
+	Name string
+
+	// Files are the configuration files defining the profile, in priority order
+	Files []string
+
+	// Keys are the configuration keys the profile sets, in all files defining the profile
+	Keys []string
+
+	// Extends are the profiles this profile extends, when Config.ProfileExtendsKey is set
+	Extends []string
+}
+
+// ListProfiles returns all profiles defined in the configuration files that would be loaded, including files they
+// include, sorted by name
+func ListProfiles(cfg Config) ([]Profile, error) {
+	if cfg.ProfileKey == "" {
+		return nil, nil
+	}
+
+	files, err := findConfigurationFiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+	r := newConfigReader(cfg, Provenance{})
+	for _, f := range files {
+		if err := r.readFile(f, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	byName := map[string]*Profile{}
+	for _, f := range r.files {
+		v, err := readConfigFile(cfg, f)
+		if err != nil {
+			return nil, err
+		}
+		profiles, _ := v.AllSettings()[strings.ToLower(cfg.ProfileKey)].(map[string]any)
+		for name, value := range profiles {
+			profileVals, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			p := byName[name]
+			if p == nil {
+				p = &Profile{Name: name}
+				byName[name] = p
+			}
+			p.Files = append(p.Files, f)
+			for _, key := range flattenKeys(profileValues(cfg, profileVals)) {
+				if !slices.Contains(p.Keys, key) {
+					p.Keys = append(p.Keys, key)
+				}
+			}
+			extends, err := profileExtends(cfg, name, profileVals)
+			if err != nil {
+				return nil, err
+			}
+			for _, parent := range extends {
+				if !slices.Contains(p.Extends, parent) {
+					p.Extends = append(p.Extends, parent)
+				}
+			}
+		}
+	}
+
+	out := make([]Profile, 0, len(byName))
+	for _, p := range byName {
+		sort.Strings(p.Keys)
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// RegisterProfileCompletion registers shell completion of the --profile flag on the command, completing the profiles
+// returned by ListProfiles. cfg is used when completing, so configuration files set by flags such as --config are
+// used when AddFlags has been called with the same Config
+func RegisterProfileCompletion(cfg *Config, cmd *cobra.Command) error {
+	return cmd.RegisterFlagCompletionFunc("profile", func(_ *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		profiles, err := ListProfiles(*cfg)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var out []cobra.Completion
+		for _, p := range profiles {
+			if strings.HasPrefix(p.Name, strings.ToLower(toComplete)) {
+				out = append(out, cobra.CompletionWithDesc(p.Name, "defined in "+strings.Join(p.Files, ", ")))
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// ProfileActivation automatically activates a profile based on an environment variable, such as a ci profile
+// when CI=true
+type ProfileActivation struct {
+	// Profile is the name of the profile to activate
+	Profile string
+
+	// EnvVar is the environment variable which activates the profile
+	EnvVar string
+
+	// Value is the value of the environment variable which activates the profile, when empty the profile is activated
+	// by any non-empty value
+	Value string
+}
+
+// ActivateProfileOnEnv returns a ProfileActivation for the profile when the environment variable is set to the value,
+// or to any non-empty value if the value is empty
+func ActivateProfileOnEnv(profile, envVar, value string) ProfileActivation {
+	return ProfileActivation{
+		Profile: profile,
+		EnvVar:  envVar,
+		Value:   value,
+	}
+}
+
+// active returns true when the environment variable is set to the activating value
+func (a ProfileActivation) active(cfg Config) bool {
+	value, ok := lookupEnv(cfg, a.EnvVar)
+	if !ok || value == "" {
+		return false
+	}
+	return a.Value == "" || strings.EqualFold(a.Value, value)
+}
+
+// selectProfiles returns the profiles to apply, in order. Profiles requested by Config.Profiles, set using the
+// --profile flag or the <APP>_PROFILE environment variable, take precedence over all others: when any are requested,
+// only these are used. Otherwise, the default profiles listed with the DefaultProfilesKey in the configuration files
+// are used, followed by profiles activated by ProfileActivations which are defined in the configuration files
+func selectProfiles(cfg Config, settings map[string]any) ([]string, error) {
+	if requested := Flatten(cfg.Profiles...); len(requested) > 0 {
+		return requested, nil
+	}
+
+	var out []string
+	if cfg.DefaultProfilesKey != "" {
+		value := settings[strings.ToLower(cfg.DefaultProfilesKey)]
+		names, ok := profileNames(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid '%s', expected a profile name or list of names: %v", cfg.DefaultProfilesKey, value)
+		}
+		out = append(out, names...)
+	}
+
+	profiles, _ := settings[strings.ToLower(cfg.ProfileKey)].(map[string]any)
+	for _, activation := range cfg.ProfileActivations {
+		name := strings.ToLower(activation.Profile)
+		if _, defined := profiles[name]; !defined || slices.Contains(out, name) || !activation.active(cfg) {
+			continue
+		}
+		cfg.Logger.Debugf("activating profile %s from environment variable %s", name, activation.EnvVar)
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+// profileNames returns the profile names from a configuration value, which may be a single name, a comma-separated
+// list of names or a list of names
+func profileNames(value any) ([]string, bool) {
+	var names []string
+	switch value := value.(type) {
+	case nil:
+	case string:
+		names = Flatten(value)
+	case []any:
+		for _, entry := range value {
+			name, ok := entry.(string)
+			if !ok {
+				return nil, false
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, false
+	}
+	for i := range names {
+		names[i] = strings.ToLower(names[i])
+	}
+	return names, true
+}
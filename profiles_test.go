@@ -0,0 +1,153 @@
+package fangs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/go-logger/adapter/discard"
+)
+
+func Test_SelectProfiles(t *testing.T) {
+	type config struct {
+		Name  string `mapstructure:"name"`
+		Depth int    `mapstructure:"depth"`
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want config
+	}{
+		{
+			name: "default profiles",
+			want: config{Name: "local", Depth: 1},
+		},
+		{
+			name: "activated profiles after default profiles",
+			env:  map[string]string{"CI": "true"},
+			want: config{Name: "local", Depth: 5},
+		},
+		{
+			name: "activation value does not match",
+			env:  map[string]string{"CI": "false", "RELEASE": ""},
+			want: config{Name: "local", Depth: 1},
+		},
+		{
+			name: "activated by any value",
+			env:  map[string]string{"CI": "TRUE", "RELEASE": "1.0"},
+			want: config{Name: "local", Depth: 10},
+		},
+		{
+			name: "env var replaces default and activated profiles",
+			env:  map[string]string{"CI": "true", "APP_PROFILE": "release"},
+			want: config{Name: "default", Depth: 10},
+		},
+		{
+			name: "flag replaces env var",
+			env:  map[string]string{"CI": "true", "APP_PROFILE": "release"},
+			args: []string{"--profile", "ci"},
+			want: config{Name: "default", Depth: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig("app")
+			cfg.LookupEnv = func(key string) (string, bool) {
+				value, ok := tt.env[key]
+				return value, ok
+			}
+			cfg.Files = []string{"test-fixtures/default-profiles/app.yaml"}
+			cfg.DefaultProfilesKey = "default-profiles"
+			cfg.ProfileActivations = []ProfileActivation{
+				ActivateProfileOnEnv("ci", "CI", "true"),
+				ActivateProfileOnEnv("release", "RELEASE", ""),
+				ActivateProfileOnEnv("undefined", "CI", ""),
+			}
+			cfg.Strict = true
+			cfg = cfg.WithProfileEnvVar()
+
+			cmd := &cobra.Command{}
+			cfg.AddFlags(NewPFlagSet(discard.New(), cmd.Flags()))
+			require.NoError(t, cmd.Flags().Parse(tt.args))
+
+			c := &config{}
+			require.NoError(t, Load(cfg, cmd, c))
+			require.Equal(t, tt.want, *c)
+		})
+	}
+}
+
+func Test_profileNames(t *testing.T) {
+	names, ok := profileNames("CI, release")
+	require.True(t, ok)
+	require.Equal(t, []string{"ci", "release"}, names)
+
+	names, ok = profileNames([]any{"ci", "Release"})
+	require.True(t, ok)
+	require.Equal(t, []string{"ci", "release"}, names)
+
+	_, ok = profileNames(map[string]any{"ci": true})
+	require.False(t, ok)
+}
+
+func Test_ListProfiles(t *testing.T) {
+	cfg := NewConfig("app")
+	cfg.IncludeKey = "include"
+	cfg.ProfileExtendsKey = "extends"
+	cfg.Files = []string{"test-fixtures/list-profiles/app.yaml"}
+
+	profiles, err := ListProfiles(cfg)
+	require.NoError(t, err)
+	require.Equal(t, []Profile{
+		{
+			Name:  "ci",
+			Files: []string{"test-fixtures/list-profiles/app.yaml", "test-fixtures/list-profiles/base.yaml"},
+			Keys:  []string{"depth", "name"},
+		},
+		{
+			Name:    "ci-strict",
+			Files:   []string{"test-fixtures/list-profiles/app.yaml"},
+			Keys:    []string{"scanning.fail-on"},
+			Extends: []string{"ci"},
+		},
+		{
+			Name:  "dev",
+			Files: []string{"test-fixtures/list-profiles/base.yaml"},
+			Keys:  []string{"name"},
+		},
+	}, profiles)
+
+	type config struct {
+		Name string `mapstructure:"name"`
+	}
+	cfg.Profiles = []string{"staging"}
+	err = Load(cfg, &cobra.Command{}, &config{})
+	require.ErrorContains(t, err, "profile not found in any configuration files: staging, available profiles: ci, ci-strict, dev")
+}
+
+func Test_RegisterProfileCompletion(t *testing.T) {
+	cfg := NewConfig("app")
+	cfg.IncludeKey = "include"
+
+	root := &cobra.Command{Use: "app", Run: func(*cobra.Command, []string) {}}
+	cfg.AddFlags(NewPFlagSet(discard.New(), root.Flags()))
+	require.NoError(t, RegisterProfileCompletion(&cfg, root))
+
+	out := &strings.Builder{}
+	root.SetOut(out)
+	root.SetErr(&strings.Builder{})
+	root.SetArgs([]string{cobra.ShellCompRequestCmd, "--config", "test-fixtures/list-profiles/app.yaml", "--profile", "c"})
+	require.NoError(t, root.Execute())
+
+	require.Equal(t, strings.Join([]string{
+		"ci\tdefined in test-fixtures/list-profiles/app.yaml, test-fixtures/list-profiles/base.yaml",
+		"ci-strict\tdefined in test-fixtures/list-profiles/app.yaml",
+		":4",
+		"",
+	}, "\n"), out.String())
+}
@@ -0,0 +1,142 @@
+package fangs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// SourceKind identifies the kind of source a configuration value was loaded from
+type SourceKind string
+
+const (
+	// SourceDefault values were not set by any source, the value is the default from the configuration struct
+	SourceDefault SourceKind = "default"
+	// SourceFile values were read from a configuration file
+	SourceFile SourceKind = "file"
+	// SourceProfile values were read from a profile section of a configuration file
+	SourceProfile SourceKind = "profile"
+	// SourceEnv values were read from an environment variable
+	SourceEnv SourceKind = "env"
+	// SourceFlag values were read from a command-line flag
+	SourceFlag SourceKind = "flag"
+	// SourceCustom values were read from a Source in Config.Sources
+	SourceCustom SourceKind = "source"
+)
+
+// Origin describes where a configuration value was loaded from
+type Origin struct {
+	// Kind is the kind of source the value was loaded from
+	Kind SourceKind
+
+	// File is the configuration file the value was read from, set for file and profile values
+	File string
+
+	// Profile is the name of the profile the value was read from, set for profile values
+	Profile string
+
+	// EnvVar is the name of the environment variable the value was read from, set for env values
+	EnvVar string
+
+	// Flag is the name of the flag the value was read from, set for flag values
+	Flag string
+
+	// Source is the name of the Source the value was read from, set for custom source values
+	Source string
+}
+
+// String returns a short description of the origin, e.g. "/etc/xdg/app/config.yaml (profile: ci)" or "flag --depth"
+func (o Origin) String() string {
+	switch o.Kind {
+	case SourceFile:
+		return o.File
+	case SourceProfile:
+		if o.File == "" {
+			return fmt.Sprintf("profile %s", o.Profile)
+		}
+		return fmt.Sprintf("%s (profile: %s)", o.File, o.Profile)
+	case SourceEnv:
+		return fmt.Sprintf("env %s", o.EnvVar)
+	case SourceFlag:
+		return fmt.Sprintf("flag --%s", o.Flag)
+	case SourceCustom:
+		return o.Source
+	}
+	return string(o.Kind)
+}
+
+// Provenance records the Origin of each configuration value, keyed by the lowercase, dot-separated configuration path,
+// such as "scanning.depth"
+type Provenance map[string]Origin
+
+// LoadWithProvenance loads configurations the same as Load, additionally returning where each value was loaded from
+func LoadWithProvenance(cfg Config, cmd *cobra.Command, configurations ...any) (Provenance, error) {
+	prov := Provenance{}
+	err := loadConfig(cfg, commandFlagRefs(cmd), prov, configurations...)
+	if err != nil {
+		return nil, err
+	}
+	return prov, nil
+}
+
+// Lookup returns the Origin of the value at the given configuration path. Values such as maps may have been set
+// by a parent or by their individual entries: the closest parent is used, or if all entries share the same origin,
+// that origin is returned
+func (p Provenance) Lookup(path string) (Origin, bool) {
+	path = strings.ToLower(path)
+	for key := path; key != ""; key = parentKey(key) {
+		if o, ok := p[key]; ok {
+			return o, true
+		}
+	}
+
+	var found *Origin
+	for _, key := range p.descendants(path) {
+		o := p[key]
+		if found != nil && *found != o {
+			return Origin{}, false
+		}
+		found = &o
+	}
+	if found == nil {
+		return Origin{}, false
+	}
+	return *found, true
+}
+
+// set records the origin for the path, replacing any origins recorded for values nested below it
+func (p Provenance) set(path string, o Origin) {
+	path = strings.ToLower(path)
+	for _, key := range p.descendants(path) {
+		delete(p, key)
+	}
+	p[path] = o
+}
+
+// covers returns true if an origin has been recorded for the path or any value nested below it
+func (p Provenance) covers(path string) bool {
+	path = strings.ToLower(path)
+	if _, ok := p[path]; ok {
+		return true
+	}
+	return len(p.descendants(path)) > 0
+}
+
+func (p Provenance) descendants(path string) (out []string) {
+	prefix := path + "."
+	for key := range p {
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+func parentKey(key string) string {
+	idx := strings.LastIndex(key, ".")
+	if idx < 0 {
+		return ""
+	}
+	return key[:idx]
+}
@@ -0,0 +1,103 @@
+package fangs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadWithProvenance(t *testing.T) {
+	type holder struct {
+		V     string `mapstructure:"v"`
+		Sub   *sub   `mapstructure:"sub"`
+		Other string `mapstructure:"other"`
+		Env   string `mapstructure:"env"`
+	}
+
+	t.Setenv("MY_APP_ENV", "env-value")
+
+	cmd, cfg, _, _ := setup(t)
+	cfg.Files = []string{"test-fixtures/basic-profiles/1.yaml", "test-fixtures/basic-profiles/2.yaml"}
+	cfg.Profiles = []string{"my-profile-1"}
+
+	h := &holder{}
+	cmd.Flags().StringVarP(&h.Other, "other", "", "", "other usage")
+	require.NoError(t, cmd.Flags().Set("other", "flag-value"))
+
+	prov, err := LoadWithProvenance(cfg, cmd, h)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		expected Origin
+	}{
+		{
+			path:     "v",
+			expected: Origin{Kind: SourceProfile, File: "test-fixtures/basic-profiles/1.yaml", Profile: "my-profile-1"},
+		},
+		{
+			path:     "sub.sv",
+			expected: Origin{Kind: SourceProfile, File: "test-fixtures/basic-profiles/2.yaml", Profile: "my-profile-1"},
+		},
+		{
+			path:     "profiles.my-profile-2.v",
+			expected: Origin{Kind: SourceFile, File: "test-fixtures/basic-profiles/2.yaml"},
+		},
+		{
+			path:     "sub.unbound",
+			expected: Origin{Kind: SourceDefault},
+		},
+		{
+			path:     "other",
+			expected: Origin{Kind: SourceFlag, Flag: "other"},
+		},
+		{
+			path:     "ENV",
+			expected: Origin{Kind: SourceEnv, EnvVar: "MY_APP_ENV"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, ok := prov.Lookup(test.path)
+			require.True(t, ok)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+
+	_, ok := prov.Lookup("not-a-key")
+	require.False(t, ok)
+}
+
+func Test_ProvenanceLookup(t *testing.T) {
+	file := Origin{Kind: SourceFile, File: "a.yaml"}
+	env := Origin{Kind: SourceEnv, EnvVar: "APP_MAP"}
+
+	prov := Provenance{}
+	prov.set("same.a", file)
+	prov.set("same.b", file)
+	prov.set("mixed.a", file)
+	prov.set("mixed.b", Origin{Kind: SourceFile, File: "b.yaml"})
+	prov.set("replaced.a", file)
+	prov.set("replaced", env)
+
+	got, ok := prov.Lookup("same")
+	require.True(t, ok)
+	assert.Equal(t, file, got)
+
+	_, ok = prov.Lookup("mixed")
+	assert.False(t, ok)
+
+	got, ok = prov.Lookup("replaced.a")
+	require.True(t, ok)
+	assert.Equal(t, env, got)
+}
+
+func Test_OriginString(t *testing.T) {
+	assert.Equal(t, "default", Origin{Kind: SourceDefault}.String())
+	assert.Equal(t, "/etc/app.yaml", Origin{Kind: SourceFile, File: "/etc/app.yaml"}.String())
+	assert.Equal(t, "/etc/app.yaml (profile: ci)", Origin{Kind: SourceProfile, File: "/etc/app.yaml", Profile: "ci"}.String())
+	assert.Equal(t, "env APP_DEPTH", Origin{Kind: SourceEnv, EnvVar: "APP_DEPTH"}.String())
+	assert.Equal(t, "flag --depth", Origin{Kind: SourceFlag, Flag: "depth"}.String())
+}
@@ -0,0 +1,191 @@
+package fangs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document describing configuration structs, suitable for editor autocompletion and
+// validation of configuration files, e.g. with the yaml-language-server
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+}
+
+// Schema returns a JSON Schema describing the configuration values, with descriptions from the DescriptionProvider
+// and defaults from the current values
+func Schema(cfg Config, descriptions DescriptionProvider, values ...any) *JSONSchema {
+	root := &JSONSchema{
+		Schema:     jsonSchemaDraft,
+		Title:      cfg.AppName,
+		Type:       "object",
+		Properties: map[string]*JSONSchema{},
+	}
+	for _, value := range values {
+		v, t := base(reflect.ValueOf(value))
+		if !isStruct(t) {
+			panic(fmt.Sprintf("Schema requires struct types, got: %#v", value))
+		}
+		schemaFields(cfg, descriptions, root, v, t, true, nil)
+	}
+	if cfg.ProfileKey != "" {
+		root.Properties[cfg.ProfileKey] = &JSONSchema{
+			Description:          "configuration profiles, which override values when selected",
+			Type:                 "object",
+			AdditionalProperties: &JSONSchema{Ref: "#"},
+		}
+	}
+	return root
+}
+
+// SchemaCommand returns a JSON Schema describing the configuration values, using the same descriptions as
+// SummarizeCommand
+func SchemaCommand(cfg Config, cmd *cobra.Command, values ...any) *JSONSchema {
+	return Schema(cfg, commandDescriptions(cfg, cmd, values...), values...)
+}
+
+// schemaFields adds properties to the object schema for each field in the struct, including defaults from the field
+// values when withDefaults is set
+func schemaFields(cfg Config, descriptions DescriptionProvider, obj *JSONSchema, v reflect.Value, t reflect.Type, withDefaults bool, visiting []reflect.Type) {
+	visiting = append(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, squash, ok := outputFieldKey(cfg, f)
+		if !ok {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if squash {
+			fv, ft := base(fieldValue)
+			if isPtr(fv.Type()) && fv.IsNil() {
+				fv = reflect.New(ft).Elem()
+			}
+			if isStruct(ft) && !slices.Contains(visiting, ft) {
+				schemaFields(cfg, descriptions, obj, fv, ft, withDefaults, visiting)
+			}
+			continue
+		}
+
+		s := schemaValue(cfg, descriptions, fieldValue, withDefaults, visiting)
+		if s == nil {
+			continue
+		}
+		s.Description = strings.TrimSpace(descriptions.GetDescription(fieldValue, f))
+		obj.Properties[name] = s
+	}
+}
+
+// schemaValue returns the schema for a single value based on its type, or nil if the type is not representable
+func schemaValue(cfg Config, descriptions DescriptionProvider, value reflect.Value, withDefaults bool, visiting []reflect.Type) *JSONSchema {
+	v, t := base(value)
+
+	nullable := isPtr(value.Type()) && !isStruct(t)
+	s := &JSONSchema{}
+
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		s.Type = []string{"string", "integer"}
+	case isStruct(t):
+		s.Type = "object"
+		if slices.Contains(visiting, t) {
+			// recursive type, don't describe properties again
+			return s
+		}
+		s.Properties = map[string]*JSONSchema{}
+		if isPtr(v.Type()) && v.IsNil() {
+			v = reflect.New(t).Elem()
+		}
+		schemaFields(cfg, descriptions, s, v, t, withDefaults, visiting)
+		return s
+	case isSlice(t) || t.Kind() == reflect.Array:
+		s.Type = "array"
+		// elements are zero values, which are not meaningful defaults
+		s.Items = schemaValue(cfg, descriptions, reflect.New(t.Elem()).Elem(), false, visiting)
+	case isMap(t):
+		s.Type = "object"
+		s.AdditionalProperties = schemaValue(cfg, descriptions, reflect.New(t.Elem()).Elem(), false, visiting)
+	default:
+		typeName := jsonSchemaType(t)
+		if typeName == "" {
+			if t.Kind() == reflect.Interface {
+				// any value is accepted
+				return s
+			}
+			return nil
+		}
+		s.Type = typeName
+	}
+
+	if nullable {
+		s.Type = append(toSlice(s.Type), "null")
+	}
+
+	if withDefaults {
+		s.Default = schemaDefault(v)
+	}
+	return s
+}
+
+// jsonSchemaType returns the JSON Schema type name for a scalar type
+func jsonSchemaType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	default:
+	}
+	return ""
+}
+
+// schemaDefault returns the value to use as a default in the schema, only including scalars and slices of scalars
+func schemaDefault(v reflect.Value) any {
+	if isNil(v) || !v.CanInterface() {
+		return nil
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	t := v.Type()
+	if isSlice(t) {
+		if v.Len() == 0 || jsonSchemaType(t.Elem()) == "" {
+			return nil
+		}
+		return v.Interface()
+	}
+	if jsonSchemaType(t) == "" {
+		return nil
+	}
+	return v.Interface()
+}
+
+func toSlice(typ any) []string {
+	switch typ := typ.(type) {
+	case string:
+		return []string{typ}
+	case []string:
+		return typ
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package fangs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SchemaCommand(t *testing.T) {
+	type item struct {
+		Name string `mapstructure:"name" description:"item name"`
+	}
+	type squashed struct {
+		Squashed bool `mapstructure:"squashed"`
+	}
+	type recursive struct {
+		Name  string     `mapstructure:"name"`
+		Child *recursive `mapstructure:"child"`
+	}
+	type config struct {
+		squashed `mapstructure:",squash"`
+		Depth    int               `mapstructure:"depth"`
+		Ratio    *float64          `mapstructure:"ratio"`
+		Timeout  time.Duration     `mapstructure:"timeout"`
+		Items    []item            `mapstructure:"items"`
+		Labels   map[string]string `mapstructure:"labels"`
+		Tree     recursive         `mapstructure:"tree"`
+		Ignored  string            `mapstructure:"-"`
+		Hidden   string            `yaml:"-" mapstructure:"hidden"`
+		Exclude  []string
+	}
+
+	c := &config{
+		Depth:   2,
+		Timeout: time.Minute,
+		Exclude: []string{"**/*.tmp"},
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().IntVarP(&c.Depth, "depth", "d", c.Depth, "scanning depth")
+
+	cfg := NewConfig("app")
+	schema := SchemaCommand(cfg, cmd, c)
+
+	got, err := json.MarshalIndent(schema, "", "  ")
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "app",
+  "type": "object",
+  "properties": {
+    "squashed": {"type": "boolean", "default": false},
+    "depth": {"description": "scanning depth", "type": "integer", "default": 2},
+    "ratio": {"type": ["number", "null"]},
+    "timeout": {"type": ["string", "integer"], "default": "1m0s"},
+    "items": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": {"description": "item name", "type": "string"}
+        }
+      }
+    },
+    "labels": {
+      "type": "object",
+      "additionalProperties": {"type": "string"}
+    },
+    "tree": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string", "default": ""},
+        "child": {"type": "object"}
+      }
+    },
+    "Exclude": {"type": "array", "items": {"type": "string"}, "default": ["**/*.tmp"]},
+    "profiles": {
+      "description": "configuration profiles, which override values when selected",
+      "type": "object",
+      "additionalProperties": {"$ref": "#"}
+    }
+  }
+}`, string(got))
+}
+
+func Test_SchemaNilStructPointers(t *testing.T) {
+	type sub struct {
+		Name string `mapstructure:"name"`
+	}
+	type config struct {
+		Ptr     *sub            `mapstructure:"ptr"`
+		Ptrs    []*sub          `mapstructure:"ptrs"`
+		PtrsMap map[string]*sub `mapstructure:"ptrs-map"`
+	}
+
+	schema := Schema(NewConfig("app"), NewStructDescriptionTagProvider(), &config{})
+
+	got, err := json.MarshalIndent(schema, "", "  ")
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "app",
+  "type": "object",
+  "properties": {
+    "ptr": {"type": "object", "properties": {"name": {"type": "string", "default": ""}}},
+    "ptrs": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}}}},
+    "ptrs-map": {"type": "object", "additionalProperties": {"type": "object", "properties": {"name": {"type": "string"}}}},
+    "profiles": {"description": "configuration profiles, which override values when selected", "type": "object", "additionalProperties": {"$ref": "#"}}
+  }
+}`, string(got))
+}
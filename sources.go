@@ -0,0 +1,291 @@
+package fangs
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Source provides configuration values to be merged with the values of all other sources, such as defaults embedded
+// in an application or values provided by a CI system. Sources are added to Config.Sources and are merged with the
+// built-in sources: configuration files (FilesPriority), environment variables (EnvPriority), flags (FlagsPriority)
+// and overrides (OverridesPriority)
+type Source interface {
+	// Name describes the source, which is included in the provenance of the values and in errors
+	Name() string
+
+	// Priority determines which source a value is taken from when set by multiple sources: values from sources with
+	// a higher priority replace values from sources with a lower priority, nested maps are merged. Sources with the
+	// same priority are applied in order, with the built-in sources first, so later sources take precedence
+	Priority() int
+
+	// Read returns the configuration values as nested maps keyed by configuration path segments, e.g.
+	// {"scanning": {"depth": 5}}; keys are case-insensitive
+	Read() (map[string]any, error)
+}
+
+const (
+	// FilesPriority is the priority of values read from configuration files, including profiles
+	FilesPriority = 100
+
+	// EnvPriority is the priority of values read from environment variables
+	EnvPriority = 200
+
+	// FlagsPriority is the priority of values read from flags that have been set
+	FlagsPriority = 300
+
+	// OverridesPriority is the priority of values from Config.Overrides, set using the Config.SetFlag flag
+	OverridesPriority = 400
+)
+
+// originProvider is implemented by sources that record the origin of each value they read, otherwise all values are
+// recorded as being from the source
+type originProvider interface {
+	origins() Provenance
+}
+
+// configPath is the path of a configuration value, which may be set by an environment variable or a flag
+type configPath struct {
+	path   []string
+	envVar string
+	flag   *pflag.Flag
+	typ    reflect.Type
+
+	// merge and mergeKey are set by the merge struct tag
+	merge    MergeStrategy
+	mergeKey string
+}
+
+// readSources reads all sources in priority order, returning the merged values and recording the origin of each value
+func readSources(sources []Source, prov Provenance, merge mergeConfig) (map[string]any, error) {
+	sources = append([]Source(nil), sources...)
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority() < sources[j].Priority()
+	})
+
+	values := map[string]any{}
+	for _, source := range sources {
+		incoming, err := source.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read configuration from %s: %w", source.Name(), err)
+		}
+		incoming = lowerKeys(incoming)
+
+		origins := Provenance{}
+		if p, ok := source.(originProvider); ok {
+			origins = p.origins()
+		} else {
+			for _, key := range flattenKeys(incoming) {
+				origins.set(key, Origin{Kind: SourceCustom, Source: source.Name()})
+			}
+		}
+		for key, origin := range origins {
+			prov.set(key, origin)
+		}
+
+		mergeValues(values, incoming, merge, MergeReplace)
+	}
+	return values, nil
+}
+
+// fileSource reads values from the configuration files found, including selected profiles
+type fileSource struct {
+	cfg   Config
+	merge mergeConfig
+	files []string
+	prov  Provenance
+}
+
+var _ interface {
+	Source
+	originProvider
+} = (*fileSource)(nil)
+
+func (s *fileSource) Name() string {
+	return "files"
+}
+
+func (s *fileSource) Priority() int {
+	return FilesPriority
+}
+
+func (s *fileSource) Read() (map[string]any, error) {
+	files, err := findConfigurationFiles(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.files = files
+
+	// configuration files are merged with the following behavior: each configuration file is loaded, in priority
+	// order where the first takes precedence if the same key is defined in multiple files. lists and map
+	// configurations will have values appended, and profiles will overwrite values
+	s.prov = Provenance{}
+	v, err := readConfigurationFiles(s.cfg, files, s.prov, s.merge)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mergeProfiles(s.cfg, v, s.prov, s.merge)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.AllSettings(), nil
+}
+
+func (s *fileSource) origins() Provenance {
+	return s.prov
+}
+
+// envSource reads values from environment variables for all configuration paths
+type envSource struct {
+	cfg   Config
+	paths []configPath
+	prov  Provenance
+}
+
+var _ interface {
+	Source
+	originProvider
+} = (*envSource)(nil)
+
+func (s *envSource) Name() string {
+	return "environment variables"
+}
+
+func (s *envSource) Priority() int {
+	return EnvPriority
+}
+
+func (s *envSource) Read() (map[string]any, error) {
+	s.prov = Provenance{}
+	out := map[string]any{}
+	for _, p := range s.paths {
+		value, ok := lookupEnv(s.cfg, p.envVar)
+		if !ok {
+			continue
+		}
+		setValue(out, p.path, value)
+		s.prov.set(strings.Join(p.path, "."), Origin{Kind: SourceEnv, EnvVar: p.envVar})
+	}
+	return out, nil
+}
+
+func (s *envSource) origins() Provenance {
+	return s.prov
+}
+
+// flagSource reads values from flags that have been set, for all configuration paths with flags
+type flagSource struct {
+	paths []configPath
+	prov  Provenance
+}
+
+var _ interface {
+	Source
+	originProvider
+} = (*flagSource)(nil)
+
+func (s *flagSource) Name() string {
+	return "flags"
+}
+
+func (s *flagSource) Priority() int {
+	return FlagsPriority
+}
+
+func (s *flagSource) Read() (map[string]any, error) {
+	s.prov = Provenance{}
+	out := map[string]any{}
+	for _, p := range s.paths {
+		if p.flag == nil || !p.flag.Changed {
+			continue
+		}
+		setValue(out, p.path, flagValue(p.flag))
+		s.prov.set(strings.Join(p.path, "."), Origin{Kind: SourceFlag, Flag: p.flag.Name})
+	}
+	return out, nil
+}
+
+func (s *flagSource) origins() Provenance {
+	return s.prov
+}
+
+// flagValue returns the value of the flag, as a slice for slice and array flags, as a map for map flags, otherwise
+// as a string
+func flagValue(flag *pflag.Flag) any {
+	switch flag.Value.Type() {
+	case "stringToString":
+		return flagMapValue(flag, func(s string) (any, error) {
+			return s, nil
+		})
+	case "stringToInt":
+		return flagMapValue(flag, func(s string) (any, error) {
+			return strconv.Atoi(s)
+		})
+	}
+	if v, ok := flag.Value.(pflag.SliceValue); ok {
+		return v.GetSlice()
+	}
+	return flag.Value.String()
+}
+
+// flagMapValue parses the value of a map flag, formatted as: [a=1,b=2]
+func flagMapValue(flag *pflag.Flag, convert func(string) (any, error)) any {
+	out := map[string]any{}
+	s := strings.TrimSuffix(strings.TrimPrefix(flag.Value.String(), "["), "]")
+	if s == "" {
+		return out
+	}
+	pairs, err := csv.NewReader(strings.NewReader(s)).Read()
+	if err != nil {
+		return flag.Value.String()
+	}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return flag.Value.String()
+		}
+		v, err := convert(value)
+		if err != nil {
+			return flag.Value.String()
+		}
+		out[key] = v
+	}
+	return out
+}
+
+// setValue sets the value in nested maps at the lowercase path, creating maps as needed
+func setValue(values map[string]any, path []string, value any) {
+	for i, part := range path {
+		key := strings.ToLower(part)
+		if i == len(path)-1 {
+			values[key] = value
+			return
+		}
+		next, ok := values[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			values[key] = next
+		}
+		values = next
+	}
+}
+
+// lowerKeys returns a copy of the values with all keys of nested maps in lowercase, as configuration keys are
+// case-insensitive
+func lowerKeys(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for key, value := range values {
+		if m, ok := value.(map[string]any); ok {
+			value = lowerKeys(m)
+		}
+		out[strings.ToLower(key)] = value
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package fangs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+type testSource struct {
+	name     string
+	priority int
+	values   map[string]any
+	err      error
+}
+
+func (s testSource) Name() string {
+	return s.name
+}
+
+func (s testSource) Priority() int {
+	return s.priority
+}
+
+func (s testSource) Read() (map[string]any, error) {
+	return s.values, s.err
+}
+
+var _ Source = (*testSource)(nil)
+
+func Test_Sources(t *testing.T) {
+	type sub struct {
+		Name  string   `mapstructure:"name"`
+		Items []string `mapstructure:"items"`
+	}
+	type config struct {
+		Name  string `mapstructure:"name"`
+		Depth int    `mapstructure:"depth"`
+		Label string `mapstructure:"label"`
+		Other string `mapstructure:"other"`
+		Sub   sub    `mapstructure:"sub"`
+	}
+
+	t.Setenv("APP_DEPTH", "5")
+	t.Setenv("APP_LABEL", "")
+
+	c := &config{Name: "default", Other: "default"}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&c.Sub.Name, "sub-name", c.Sub.Name, "")
+	require.NoError(t, cmd.Flags().Parse([]string{"--sub-name", "flag"}))
+
+	cfg := NewConfig("app")
+	cfg.Files = []string{"test-fixtures/sources/app.yaml"}
+	cfg.Sources = []Source{
+		testSource{
+			name:     "embedded defaults",
+			priority: 50,
+			values: map[string]any{
+				"name":  "embedded",
+				"other": "embedded",
+				"sub":   map[string]any{"items": []string{"embedded"}},
+			},
+		},
+		testSource{
+			name:     "ci",
+			priority: 250,
+			values: map[string]any{
+				"DEPTH": 10,
+				"Sub":   map[string]any{"Name": "ci", "ITEMS": []string{"ci"}},
+			},
+		},
+	}
+
+	prov, err := LoadWithProvenance(cfg, cmd, c)
+	require.NoError(t, err)
+
+	require.Equal(t, &config{
+		Name:  "file",
+		Depth: 10,
+		Label: "",
+		Other: "embedded",
+		Sub: sub{
+			Name:  "flag",
+			Items: []string{"ci"},
+		},
+	}, c)
+
+	require.Equal(t, Origin{Kind: SourceFile, File: "test-fixtures/sources/app.yaml"}, prov["name"])
+	require.Equal(t, Origin{Kind: SourceCustom, Source: "ci"}, prov["depth"])
+	require.Equal(t, Origin{Kind: SourceEnv, EnvVar: "APP_LABEL"}, prov["label"])
+	require.Equal(t, Origin{Kind: SourceCustom, Source: "embedded defaults"}, prov["other"])
+	require.Equal(t, Origin{Kind: SourceFlag, Flag: "sub-name"}, prov["sub.name"])
+	require.Equal(t, "ci", prov["sub.items"].String())
+
+	// a source with the highest priority replaces flags
+	cfg.Sources = append(cfg.Sources, testSource{
+		name:     "overrides",
+		priority: FlagsPriority + 1,
+		values:   map[string]any{"sub": map[string]any{"name": "override"}},
+	})
+	require.NoError(t, Load(cfg, cmd, c))
+	require.Equal(t, "override", c.Sub.Name)
+}
+
+func Test_SourcesErrors(t *testing.T) {
+	type config struct {
+		Name string `mapstructure:"name"`
+	}
+
+	cfg := NewConfig("app")
+	cfg.Sources = []Source{
+		testSource{
+			name:   "broken",
+			err:    fmt.Errorf("unavailable"),
+			values: nil,
+		},
+	}
+	err := Load(cfg, &cobra.Command{}, &config{})
+	require.EqualError(t, err, "unable to read configuration from broken: unavailable")
+
+	cfg.Strict = true
+	cfg.Sources = []Source{
+		testSource{
+			name:   "ci",
+			values: map[string]any{"nmae": "typo"},
+		},
+	}
+	err = Load(cfg, &cobra.Command{}, &config{})
+	require.EqualError(t, err, "unknown configuration keys:\n  'nmae' in ci, did you mean 'name'?")
+}
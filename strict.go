@@ -0,0 +1,72 @@
+package fangs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checkUnknownKeys returns an error listing every key read from configuration files or custom sources that is not a
+// known configuration path, including where it was read from and a suggestion of the closest known key
+func checkUnknownKeys(cfg Config, prov Provenance, known set[string]) error {
+	var unknown []string
+	for key, origin := range prov {
+		if origin.Kind != SourceFile && origin.Kind != SourceProfile && origin.Kind != SourceCustom {
+			continue
+		}
+		if isIgnoredKey(cfg, key) || isKnownKey(key, known) {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	candidates := make([]string, 0, len(known))
+	for key := range known {
+		candidates = append(candidates, key)
+	}
+	sort.Strings(candidates)
+
+	var lines []string
+	for _, key := range unknown {
+		line := fmt.Sprintf("  '%s' in %s", key, prov[key])
+		if suggestion := closestMatch(key, candidates); suggestion != "" {
+			line += fmt.Sprintf(", did you mean '%s'?", suggestion)
+		}
+		lines = append(lines, line)
+	}
+
+	return fmt.Errorf("unknown configuration keys:\n%s", strings.Join(lines, "\n"))
+}
+
+// isIgnoredKey returns true for keys used by fangs itself rather than any configuration struct
+func isIgnoredKey(cfg Config, key string) bool {
+	if key == "config" {
+		return true
+	}
+	if cfg.DefaultProfilesKey != "" && isKeyOrParent(strings.ToLower(cfg.DefaultProfilesKey), key) {
+		return true
+	}
+	return cfg.ProfileKey != "" && isKeyOrParent(strings.ToLower(cfg.ProfileKey), key)
+}
+
+// isKnownKey returns true if the key is a known configuration path, is nested within a known path such as an entry
+// in a map, or is a parent of a known path such as an empty section
+func isKnownKey(key string, known set[string]) bool {
+	for k := range known {
+		if isKeyOrParent(k, key) || isKeyOrParent(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeyOrParent returns true if parent is the same key as key or one of its parents
+func isKeyOrParent(parent, key string) bool {
+	return key == parent || strings.HasPrefix(key, parent+".")
+}
@@ -0,0 +1,69 @@
+package fangs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type strictOther struct {
+	Other  string            `mapstructure:"other"`
+	Labels map[string]string `mapstructure:"labels"`
+}
+
+func Test_StrictUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		strict  bool
+		wantErr string
+	}{
+		{
+			name:   "unknown keys ignored when not strict",
+			files:  []string{"test-fixtures/strict/typos.yaml"},
+			strict: false,
+		},
+		{
+			name:   "unknown keys reported",
+			files:  []string{"test-fixtures/strict/typos.yaml"},
+			strict: true,
+			wantErr: `unknown configuration keys:
+  'scaning.depth' in test-fixtures/strict/typos.yaml
+  'sub.unbond' in test-fixtures/strict/typos.yaml, did you mean 'sub.unbound'?`,
+		},
+		{
+			name:   "known keys, map entries and profiles allowed",
+			files:  []string{"test-fixtures/strict/valid.yaml"},
+			strict: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, cfg, r, _ := setup(t)
+			cfg.Files = test.files
+			cfg.Strict = test.strict
+
+			o := &strictOther{}
+			err := Load(cfg, cmd, r, o)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "strict-v", r.V)
+			require.Equal(t, "strict-other", o.Other)
+		})
+	}
+}
+
+func Test_StrictUnknownProfileKeys(t *testing.T) {
+	cmd, cfg, r, _ := setup(t)
+	cfg.Files = []string{"test-fixtures/strict/valid.yaml"}
+	cfg.Profiles = []string{"any-profile"}
+	cfg.Strict = true
+
+	err := Load(cfg, cmd, r, &strictOther{})
+	require.EqualError(t, err, `unknown configuration keys:
+  'anything' in test-fixtures/strict/valid.yaml (profile: any-profile)`)
+}
@@ -15,10 +15,16 @@ import (
 var trailingSpace = regexp.MustCompile(`[ \r]+\n`)
 
 func Summarize(cfg Config, descriptions DescriptionProvider, filter ValueFilterFunc, values ...any) string {
+	return SummarizeWithProvenance(cfg, descriptions, filter, nil, values...)
+}
+
+// SummarizeWithProvenance summarizes the same as Summarize, additionally adding a comment to each value with
+// where it was loaded from, such as: # from: flag --depth
+func SummarizeWithProvenance(cfg Config, descriptions DescriptionProvider, filter ValueFilterFunc, prov Provenance, values ...any) string {
 	root := &section{}
 	for _, value := range values {
 		v := reflect.ValueOf(value)
-		summarize(cfg, descriptions, root, v, nil)
+		summarize(cfg, descriptions, prov, root, v, nil)
 	}
 	if filter == nil {
 		filter = func(s string) string {
@@ -30,16 +36,27 @@ func Summarize(cfg Config, descriptions DescriptionProvider, filter ValueFilterF
 }
 
 func SummarizeCommand(cfg Config, cmd *cobra.Command, filter ValueFilterFunc, values ...any) string {
+	return SummarizeCommandWithProvenance(cfg, cmd, filter, nil, values...)
+}
+
+// SummarizeCommandWithProvenance summarizes the same as SummarizeCommand, additionally adding a comment to each value
+// with where it was loaded from, using the Provenance returned by LoadWithProvenance
+func SummarizeCommandWithProvenance(cfg Config, cmd *cobra.Command, filter ValueFilterFunc, prov Provenance, values ...any) string {
+	return SummarizeWithProvenance(cfg, commandDescriptions(cfg, cmd, values...), filter, prov, values...)
+}
+
+// commandDescriptions returns the descriptions used when summarizing a command: field descriptions, description tags
+// and flag usage of all commands in the root command's tree
+func commandDescriptions(cfg Config, cmd *cobra.Command, values ...any) DescriptionProvider {
 	root := cmd
 	for root.Parent() != nil {
 		root = root.Parent()
 	}
-	descriptions := DescriptionProviders(
+	return DescriptionProviders(
 		NewFieldDescriber(values...),
 		NewStructDescriptionTagProvider(),
 		NewCommandFlagDescriptionProvider(cfg.TagName, root),
 	)
-	return Summarize(cfg, descriptions, filter, values...)
 }
 
 func SummarizeLocations(cfg Config) (out []string) {
@@ -49,64 +66,49 @@ func SummarizeLocations(cfg Config) (out []string) {
 	return
 }
 
+// LocationExists returns true if the location, such as one returned by SummarizeLocations, is an existing file in the
+// Config.FS, with a leading ~ expanded to the home directory using Config.LookupEnv and relative locations within
+// Config.WorkDir
+func LocationExists(cfg Config, location string) bool {
+	file, err := expandHome(cfg, location)
+	if err != nil {
+		return false
+	}
+	return fileExistsFS(configFS(cfg), workDirPath(cfg, file))
+}
+
 type ValueFilterFunc func(string) string
 
-func summarize(cfg Config, descriptions DescriptionProvider, s *section, value reflect.Value, path []string) {
+func summarize(cfg Config, descriptions DescriptionProvider, prov Provenance, s *section, value reflect.Value, path []string) {
 	v, t := base(value)
 
 	if !isStruct(t) {
 		panic(fmt.Sprintf("Summarize requires struct types, got: %#v", value.Interface()))
 	}
 
-	summarizeFields(cfg, descriptions, s, v, t, path)
+	summarizeFields(cfg, descriptions, prov, s, v, t, path)
 }
 
-func summarizeFields(cfg Config, descriptions DescriptionProvider, s *section, v reflect.Value, t reflect.Type, path []string) {
+func summarizeFields(cfg Config, descriptions DescriptionProvider, prov Provenance, s *section, v reflect.Value, t reflect.Type, path []string) {
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
-		if !includeField(f) {
+		name, squash, ok := outputFieldKey(cfg, f)
+		if !ok {
 			continue
 		}
 
 		currentPath := path
-		name := f.Name
-
-		// should we ignore this field based on the output tag?
-		if cfg.TagName != "yaml" {
-			if tag, ok := f.Tag.Lookup("yaml"); ok {
-				parts := strings.Split(tag, ",")
-				if parts[0] == "-" {
-					continue
-				}
-			}
-		}
-
-		if tag, ok := f.Tag.Lookup(cfg.TagName); ok {
-			parts := strings.Split(tag, ",")
-			tag = parts[0]
-			if tag == "-" {
-				continue
-			}
-			switch {
-			case contains(parts, "squash"):
-				name = ""
-			case tag == "":
-				currentPath = append(currentPath, name)
-			default:
-				name = tag
-				currentPath = append(currentPath, tag)
-			}
-		} else {
+		if !squash {
 			currentPath = append(currentPath, name)
 		}
 
 		// process the field based on its type
-		summarizeField(cfg, descriptions, s, f, v.Field(i), name, currentPath)
+		summarizeField(cfg, descriptions, prov, s, f, v.Field(i), name, currentPath)
 	}
 }
 
 // summarizeField handles a single field according to its type
-func summarizeField(cfg Config, descriptions DescriptionProvider, s *section, f reflect.StructField, fieldValue reflect.Value, fieldName string, path []string) {
+func summarizeField(cfg Config, descriptions DescriptionProvider, prov Provenance, s *section, f reflect.StructField, fieldValue reflect.Value, fieldName string, path []string) {
 	v, t := base(fieldValue)
 
 	if isStruct(t) {
@@ -119,7 +121,7 @@ func summarizeField(cfg Config, descriptions DescriptionProvider, s *section, f
 			v = reflect.New(t)
 		}
 
-		summarize(cfg, descriptions, sub, v, path)
+		summarize(cfg, descriptions, prov, sub, v, path)
 		return
 	}
 
@@ -132,11 +134,17 @@ func summarizeField(cfg Config, descriptions DescriptionProvider, s *section, f
 		env = ""
 	}
 
+	source := ""
+	if origin, ok := prov.Lookup(strings.Join(path, ".")); ok {
+		source = origin.String()
+	}
+
 	s.add(cfg.Logger,
 		fieldName,
 		fieldValue,
 		descriptions.GetDescription(fieldValue, f),
-		env)
+		env,
+		source)
 }
 
 // printVal prints a value in YAML format
@@ -252,6 +260,7 @@ type section struct {
 	value       reflect.Value
 	description string
 	env         string
+	source      string
 	subsections []*section
 }
 
@@ -275,12 +284,13 @@ func (s *section) sub(name string) *section {
 	return sub
 }
 
-func (s *section) add(log logger.Logger, name string, value reflect.Value, description string, env string) *section {
+func (s *section) add(log logger.Logger, name string, value reflect.Value, description string, env string, source string) *section {
 	add := &section{
 		name:        name,
 		value:       value,
 		description: description,
 		env:         env,
+		source:      source,
 	}
 	sub := s.get(name)
 	if sub != nil {
@@ -331,6 +341,9 @@ func stringifySection(cfg Config, filter ValueFilterFunc, out *bytes.Buffer, s *
 		if s.description != "" || s.env != "" {
 			out.WriteString("\n")
 		}
+		if s.source != "" {
+			out.WriteString(indent + "# from: " + s.source + "\n")
+		}
 
 		out.WriteString(indent)
 
@@ -590,9 +590,44 @@ func Test_SummarizeLocations(t *testing.T) {
 		strings.Join(allExts("/xdg-home/app/config"), "\n"),
 		strings.Join(allExts("/xdg-dir1/app/config"), "\n"),
 		strings.Join(allExts("/xdg-dir2/app/config"), "\n"),
+		strings.Join(allExts("/etc/app/config"), "\n"),
+		strings.Join(allExts("/etc/app"), "\n"),
 	}
 
 	expected := fmt.Sprintf(strings.Repeat("%s\n", len(opts)), opts...)
 
 	require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(got))
 }
+
+func Test_SummarizeCommandWithProvenance(t *testing.T) {
+	t.Setenv("MY_APP_SUB_UNBOUND", "env-unbound")
+
+	cmd, cfg, r, _ := setup(t)
+	cfg.Files = []string{"test-fixtures/basic-profiles/1.yaml", "test-fixtures/basic-profiles/2.yaml"}
+	cfg.Profiles = []string{"my-profile-1"}
+
+	require.NoError(t, cmd.Flags().Set("sv", "flag-sv"))
+
+	prov, err := LoadWithProvenance(cfg, cmd, r)
+	require.NoError(t, err)
+
+	got := SummarizeCommandWithProvenance(cfg, cmd, nil, prov, r)
+
+	want := `# v usage (env: MY_APP_V)
+# from: test-fixtures/basic-profiles/1.yaml (profile: my-profile-1)
+v: 'level-1-override'
+
+sub:
+  # sv usage (env: MY_APP_SUB_SV)
+  # from: flag --sv
+  sv: 'flag-sv'
+
+  # (env: MY_APP_SUB_UNBOUND)
+  # from: env MY_APP_SUB_UNBOUND
+  unbound: 'env-unbound'
+
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+}
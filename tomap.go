@@ -0,0 +1,92 @@
+package fangs
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ToMap returns the configuration values as nested maps keyed by the same field names as are used to load and
+// summarize the configuration, suitable for encoding as JSON; durations are formatted as strings
+func ToMap(cfg Config, values ...any) map[string]any {
+	out := map[string]any{}
+	for _, value := range values {
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("ToMap requires struct types, got: %#v", value))
+		}
+		structToMap(cfg, out, v)
+	}
+	return out
+}
+
+func structToMap(cfg Config, out map[string]any, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, squash, ok := outputFieldKey(cfg, t.Field(i))
+		if !ok {
+			continue
+		}
+
+		fv := v.Field(i)
+		if squash {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				structToMap(cfg, out, fv)
+			}
+			continue
+		}
+
+		if !fv.CanInterface() {
+			continue
+		}
+		out[name] = valueToAny(cfg, fv)
+	}
+}
+
+// valueToAny converts structs to maps, including structs within slices and maps, so that values are keyed by the
+// configured tag names
+func valueToAny(cfg Config, v reflect.Value) any {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := map[string]any{}
+		structToMap(cfg, out, v)
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, valueToAny(cfg, v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := map[string]any{}
+		i := v.MapRange()
+		for i.Next() {
+			out[fmt.Sprintf("%v", i.Key().Interface())] = valueToAny(cfg, i.Value())
+		}
+		return out
+	default:
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return v.Interface()
+}
@@ -0,0 +1,53 @@
+package fangs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ToMap(t *testing.T) {
+	type item struct {
+		Name string `mapstructure:"name"`
+	}
+	type embedded struct {
+		Level string `mapstructure:"level"`
+	}
+	type config struct {
+		embedded `mapstructure:",squash"`
+		Name     string          `mapstructure:"name"`
+		Timeout  time.Duration   `mapstructure:"timeout"`
+		Items    []item          `mapstructure:"items"`
+		ByName   map[string]item `mapstructure:"by-name"`
+		Sub      *item           `mapstructure:"sub"`
+		Secret   string          `mapstructure:"secret" yaml:"-"`
+		Ignored  string          `mapstructure:"-"`
+		Untagged bool
+	}
+
+	c := &config{
+		embedded: embedded{Level: "high"},
+		Name:     "app",
+		Timeout:  time.Minute,
+		Items:    []item{{Name: "a"}},
+		ByName:   map[string]item{"b": {Name: "b"}},
+		Secret:   "secret",
+		Ignored:  "ignored",
+		Untagged: true,
+	}
+
+	require.Equal(t, map[string]any{
+		"level":    "high",
+		"name":     "app",
+		"timeout":  "1m0s",
+		"items":    []any{map[string]any{"name": "a"}},
+		"by-name":  map[string]any{"b": map[string]any{"name": "b"}},
+		"sub":      nil,
+		"Untagged": true,
+	}, ToMap(NewConfig("app"), c))
+
+	require.Panics(t, func() {
+		ToMap(NewConfig("app"), "not a struct")
+	})
+}
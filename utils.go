@@ -62,3 +62,53 @@ func isPromotedMethod(o any, method string) bool {
 	fileName, _ := f.FileLine(f.Entry())
 	return fileName == "<autogenerated>"
 }
+
+// flattenKeys returns the dot-separated paths to all leaf values in the nested settings map
+func flattenKeys(settings map[string]any, prefix ...string) (out []string) {
+	for key, value := range settings {
+		path := append(slices.Clone(prefix), key)
+		if m, ok := value.(map[string]any); ok && len(m) > 0 {
+			out = append(out, flattenKeys(m, path...)...)
+			continue
+		}
+		out = append(out, strings.Join(path, "."))
+	}
+	return out
+}
+
+// closestMatch returns the candidate most similar to value, or an empty string if no candidate is similar enough
+// to be a likely typo
+func closestMatch(value string, candidates []string) string {
+	best := ""
+	bestDistance := max(2, len(value)/4) + 1
+	for _, candidate := range candidates {
+		d := levenshtein(value, candidate)
+		if d < bestDistance {
+			best = candidate
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
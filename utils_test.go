@@ -38,3 +38,11 @@ func Test_isPromotedMethod(t *testing.T) {
 	// not a promoted method because the method doesn't exist on the struct
 	require.True(t, !isPromotedMethod(t3, "AddFlags"))
 }
+
+func Test_closestMatch(t *testing.T) {
+	candidates := []string{"scanning.depth", "scanning.exclude", "output"}
+
+	assert.Equal(t, "scanning.depth", closestMatch("scaning.depth", candidates))
+	assert.Equal(t, "output", closestMatch("outptu", candidates))
+	assert.Equal(t, "", closestMatch("something-else", candidates))
+}
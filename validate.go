@@ -0,0 +1,298 @@
+package fangs
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ValidateTag is the struct tag used to declare validation rules on configuration fields, e.g.:
+//
+//	Depth int `mapstructure:"depth" validate:"required,min=1,max=10"`
+//
+// The supported rules are:
+//   - required: the value must not be the zero value
+//   - min=N, max=N: numbers must be within the range; strings, slices and maps must have a length within the range
+//   - oneof=a b c: a non-zero value must be one of the space-separated values
+//   - file-exists: a non-empty value must be the path of an existing file
+//   - regex=EXPR: a non-empty string value must match the regular expression; this must be the last rule, as the
+//     expression may contain commas
+const ValidateTag = "validate"
+
+// ValidationError is returned when loaded configuration values are not valid, listing all validation failures
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+// ValidationIssue describes a single configuration value that is not valid
+type ValidationIssue struct {
+	// Path is the configuration path of the value, e.g. scanning.depth
+	Path string
+
+	// EnvVar is the environment variable that may be used to set the value, if any
+	EnvVar string
+
+	// Flag is the name of the flag that may be used to set the value, if any
+	Flag string
+
+	// Message describes why the value is not valid
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{"invalid configuration:"}
+	for _, issue := range e.Issues {
+		lines = append(lines, "  "+issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (i ValidationIssue) String() string {
+	var sources []string
+	if i.EnvVar != "" {
+		sources = append(sources, "env: "+i.EnvVar)
+	}
+	if i.Flag != "" {
+		sources = append(sources, "flag: --"+i.Flag)
+	}
+	out := i.Path
+	if len(sources) > 0 {
+		out += " (" + strings.Join(sources, ", ") + ")"
+	}
+	if out == "" {
+		return i.Message
+	}
+	return out + ": " + i.Message
+}
+
+// validate calls all Validator implementations and checks all validation rules on the provided value, returning
+// all issues found
+func validate(cfg Config, flags flagRefs, v reflect.Value) []ValidationIssue {
+	vd := validation{cfg: cfg, flags: flags}
+	vd.value(v, nil, true)
+	return vd.issues
+}
+
+type validation struct {
+	cfg    Config
+	flags  flagRefs
+	issues []ValidationIssue
+}
+
+func (vd *validation) add(path []string, hasEnv bool, flag string, message string) {
+	issue := ValidationIssue{
+		Path:    strings.Join(path, "."),
+		Flag:    flag,
+		Message: message,
+	}
+	if hasEnv && len(path) > 0 {
+		issue.EnvVar = envVar(vd.cfg.AppName, path...)
+	}
+	vd.issues = append(vd.issues, issue)
+}
+
+// value validates the value and anything nested within it; hasEnv is false for values within slices and maps,
+// which cannot be set by environment variables
+func (vd *validation) value(v reflect.Value, path []string, hasEnv bool) {
+	for isPtr(v.Type()) {
+		if v.IsNil() {
+			return
+		}
+		if v.CanInterface() {
+			obj := v.Interface()
+			if val, ok := obj.(Validator); ok && !isPromotedMethod(obj, "Validate") {
+				if err := val.Validate(); err != nil {
+					vd.add(path, false, "", err.Error())
+				}
+			}
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case isStruct(v.Type()):
+		vd.fields(v, path, hasEnv)
+	case isSlice(v.Type()):
+		for i := 0; i < v.Len(); i++ {
+			vd.nested(v.Index(i), indexPath(path, fmt.Sprintf("[%d]", i)), false)
+		}
+	case isMap(v.Type()):
+		i := v.MapRange()
+		for i.Next() {
+			vd.nested(i.Value(), append(slices.Clone(path), fmt.Sprintf("%v", i.Key().Interface())), false)
+		}
+	}
+}
+
+// nested validates values in slices and maps, which may not be addressable
+func (vd *validation) nested(v reflect.Value, path []string, hasEnv bool) {
+	if !isPtr(v.Type()) && !v.CanAddr() {
+		newV := reflect.New(v.Type())
+		newV.Elem().Set(v)
+		v = newV.Elem()
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	vd.value(v, path, hasEnv)
+}
+
+func (vd *validation) fields(v reflect.Value, path []string, hasEnv bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, squash, ok := fieldKey(vd.cfg, f)
+		if !ok {
+			continue
+		}
+
+		fieldPath := path
+		if !squash {
+			// squashed fields use the current path
+			fieldPath = append(slices.Clone(path), key)
+		}
+
+		fv := v.Field(i)
+
+		if rules, ok := f.Tag.Lookup(ValidateTag); ok {
+			flag := ""
+			if fv.CanAddr() {
+				if ref, ok := vd.flags[fv.Addr().Pointer()]; ok {
+					flag = ref.Name
+				}
+			}
+			for _, message := range checkRules(vd.cfg, fv, rules) {
+				vd.add(fieldPath, hasEnv, flag, message)
+			}
+		}
+
+		if isNil(fv) || !fv.CanAddr() {
+			continue
+		}
+		vd.value(fv.Addr(), fieldPath, hasEnv)
+	}
+}
+
+// checkRules returns a message for each of the rules the value does not satisfy
+func checkRules(cfg Config, v reflect.Value, rules string) (out []string) {
+	for rules != "" {
+		rule := rules
+		if strings.HasPrefix(rules, "regex=") {
+			// the regex may contain commas, so consumes the remaining rules
+			rules = ""
+		} else if idx := strings.Index(rules, ","); idx >= 0 {
+			rule = rules[:idx]
+			rules = rules[idx+1:]
+		} else {
+			rules = ""
+		}
+
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "" {
+			continue
+		}
+		if message := checkRule(cfg, v, name, arg); message != "" {
+			out = append(out, message)
+		}
+	}
+	return out
+}
+
+// checkRule returns a message if the value does not satisfy the rule, nil pointers only fail the required rule
+func checkRule(cfg Config, v reflect.Value, name, arg string) string {
+	if name == "required" {
+		if isNil(v) || v.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+
+	for isPtr(v.Type()) {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch name {
+	case "min", "max":
+		return checkRange(v, name, arg)
+	case "oneof":
+		values := strings.Fields(arg)
+		if !v.IsZero() && !slices.Contains(values, fmt.Sprintf("%v", v.Interface())) {
+			return fmt.Sprintf("must be one of: %s", strings.Join(values, ", "))
+		}
+	case "regex":
+		expr, err := regexp.Compile(arg)
+		if err != nil {
+			return fmt.Sprintf("invalid validation regex '%s': %v", arg, err)
+		}
+		if s := fmt.Sprintf("%v", v.Interface()); s != "" && !expr.MatchString(s) {
+			return fmt.Sprintf("must match: %s", arg)
+		}
+	case "file-exists":
+		if v.Kind() != reflect.String || v.String() == "" {
+			return ""
+		}
+		file, err := expandHome(cfg, v.String())
+		if err != nil || !fileExists(workDirPath(cfg, file)) {
+			return fmt.Sprintf("file does not exist: %s", v.String())
+		}
+	default:
+		return fmt.Sprintf("unknown validation rule: %s", name)
+	}
+	return ""
+}
+
+func checkRange(v reflect.Value, name, arg string) string {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Sprintf("invalid validation rule: %s=%s", name, arg)
+	}
+
+	var value float64
+	suffix := ""
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	case reflect.String:
+		value = float64(len(v.String()))
+		suffix = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		value = float64(v.Len())
+		suffix = " entries"
+	default:
+		return fmt.Sprintf("invalid validation rule for %s: %s", v.Type(), name)
+	}
+
+	switch {
+	case name == "min" && value < limit:
+		if suffix != "" {
+			return fmt.Sprintf("must have at least %s%s", arg, suffix)
+		}
+		return fmt.Sprintf("must be at least %s", arg)
+	case name == "max" && value > limit:
+		if suffix != "" {
+			return fmt.Sprintf("must have at most %s%s", arg, suffix)
+		}
+		return fmt.Sprintf("must be at most %s", arg)
+	}
+	return ""
+}
+
+// indexPath returns a copy of the path with the index appended to the last element, e.g. sub[0]
+func indexPath(path []string, index string) []string {
+	out := slices.Clone(path)
+	if len(out) == 0 {
+		return []string{index}
+	}
+	out[len(out)-1] += index
+	return out
+}
@@ -0,0 +1,151 @@
+package fangs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateItem struct {
+	Name string `mapstructure:"name" validate:"required"`
+}
+
+type validateSub struct {
+	Depth  int            `mapstructure:"depth" validate:"min=1,max=10"`
+	Format string         `mapstructure:"format" validate:"oneof=json yaml"`
+	Items  []validateItem `mapstructure:"items" validate:"max=2"`
+}
+
+func (s *validateSub) Validate() error {
+	if s.Depth > 5 && s.Format == "json" {
+		return errors.New("json format supports a maximum depth of 5")
+	}
+	return nil
+}
+
+var _ Validator = (*validateSub)(nil)
+
+type validateRoot struct {
+	Name    string       `mapstructure:"name" validate:"required"`
+	Version string       `mapstructure:"version" validate:"regex=^v[0-9]+(,[0-9]+)?$"`
+	File    string       `mapstructure:"file" validate:"file-exists"`
+	Ptr     *int         `mapstructure:"ptr" validate:"min=1"`
+	Sub     validateSub  `mapstructure:"sub"`
+	SubPtr  *validateSub `mapstructure:"sub-ptr"`
+}
+
+func Test_Validate(t *testing.T) {
+	valid := func() *validateRoot {
+		return &validateRoot{
+			Name:    "name",
+			Version: "v1,2",
+			File:    "test-fixtures/config.yaml",
+			Sub: validateSub{
+				Depth:  1,
+				Format: "json",
+				Items:  []validateItem{{Name: "item"}},
+			},
+			SubPtr: &validateSub{
+				Depth: 10,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(r *validateRoot)
+		expected []ValidationIssue
+	}{
+		{
+			name:   "valid",
+			modify: func(_ *validateRoot) {},
+		},
+		{
+			name: "all invalid",
+			modify: func(r *validateRoot) {
+				r.Name = ""
+				r.Version = "1.0"
+				r.File = "test-fixtures/does-not-exist.yaml"
+				r.Ptr = p(0)
+				r.Sub.Depth = 11
+				r.Sub.Format = "xml"
+				r.Sub.Items = []validateItem{{}, {Name: "item"}, {}}
+				r.SubPtr = &validateSub{Depth: 6, Format: "json"}
+			},
+			expected: []ValidationIssue{
+				{Path: "name", EnvVar: "APP_NAME", Flag: "name", Message: "is required"},
+				{Path: "version", EnvVar: "APP_VERSION", Message: "must match: ^v[0-9]+(,[0-9]+)?$"},
+				{Path: "file", EnvVar: "APP_FILE", Message: "file does not exist: test-fixtures/does-not-exist.yaml"},
+				{Path: "ptr", EnvVar: "APP_PTR", Message: "must be at least 1"},
+				{Path: "sub.depth", EnvVar: "APP_SUB_DEPTH", Flag: "depth", Message: "must be at most 10"},
+				{Path: "sub.format", EnvVar: "APP_SUB_FORMAT", Message: "must be one of: json, yaml"},
+				{Path: "sub.items", EnvVar: "APP_SUB_ITEMS", Message: "must have at most 2 entries"},
+				{Path: "sub.items[0].name", Message: "is required"},
+				{Path: "sub.items[2].name", Message: "is required"},
+				{Path: "sub-ptr", Message: "json format supports a maximum depth of 5"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := valid()
+			test.modify(r)
+
+			cmd := &cobra.Command{}
+			cmd.Flags().StringVarP(&r.Name, "name", "", r.Name, "name usage")
+			cmd.Flags().IntVarP(&r.Sub.Depth, "depth", "", r.Sub.Depth, "depth usage")
+
+			err := Load(NewConfig("app"), cmd, r)
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, test.expected, validationErr.Issues)
+		})
+	}
+}
+
+func Test_ValidationErrorString(t *testing.T) {
+	err := &ValidationError{
+		Issues: []ValidationIssue{
+			{Path: "scanning.depth", EnvVar: "APP_SCANNING_DEPTH", Flag: "depth", Message: "must be at least 1"},
+			{Path: "items[0].name", Message: "is required"},
+		},
+	}
+	require.EqualError(t, err, `invalid configuration:
+  scanning.depth (env: APP_SCANNING_DEPTH, flag: --depth): must be at least 1
+  items[0].name: is required`)
+}
+
+func Test_ValidateFileExistsUsesLookupEnv(t *testing.T) {
+	type config struct {
+		File string `mapstructure:"file" validate:"file-exists"`
+	}
+
+	home := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(home, "exists.yaml"), nil, 0o600))
+
+	cfg := NewConfig("app")
+	cfg.LookupEnv = func(key string) (string, bool) {
+		if key == "HOME" {
+			return home, true
+		}
+		return "", false
+	}
+
+	require.NoError(t, Load(cfg, nil, &config{File: "~/exists.yaml"}))
+
+	err := Load(cfg, nil, &config{File: "~/missing.yaml"})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, "file does not exist: ~/missing.yaml", validationErr.Issues[0].Message)
+}
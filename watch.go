@@ -0,0 +1,226 @@
+package fangs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// watchDebounce is the time to wait after a configuration file change for further changes before reloading, as
+// editors often write files in multiple steps
+var watchDebounce = 250 * time.Millisecond
+
+// Watch loads the configuration the same as Load, then watches for changes to the configuration files until the
+// context is done. Both the configuration files that were loaded and the directories searched by the Finders are
+// watched, so newly created configuration files are noticed, including those in directories created after watching
+// starts. On each change, the configuration is loaded into a fresh copy of the original values, including running
+// PostLoad and validation; only if this succeeds is onChange called with the previous and new configuration, errors
+// are logged and the previous configuration remains in use.
+// The configuration must be a struct, and onChange is called from a separate goroutine
+func Watch[T any](ctx context.Context, cfg Config, cmd *cobra.Command, configuration *T, onChange func(old, new *T)) error {
+	// keep a copy of the values before loading, so each reload starts from the same defaults
+	defaults, addresses := deepCopy(configuration)
+	flags := commandFlagRefs(cmd)
+	defaultFlags := rebaseFlagRefs(flags, addresses)
+
+	if err := loadConfig(cfg, flags, nil, configuration); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to watch configuration files: %w", err)
+	}
+
+	w := &configWatcher{
+		cfg:     cfg,
+		watcher: watcher,
+		dirs:    set[string]{},
+		missing: set[string]{},
+	}
+	w.update()
+
+	reload := func() {
+		next, nextAddresses := deepCopy(defaults)
+		if err := loadConfig(cfg, rebaseFlagRefs(defaultFlags, nextAddresses), nil, next); err != nil {
+			cfg.Logger.Warnf("unable to reload configuration: %v", err)
+			return
+		}
+		previous := configuration
+		configuration = next
+		onChange(previous, next)
+	}
+
+	go w.run(ctx, reload)
+
+	return nil
+}
+
+type configWatcher struct {
+	cfg     Config
+	watcher *fsnotify.Watcher
+	dirs    set[string]
+	files   set[string]
+
+	// confDirs are conf.d directories, any configuration fragment created in these is loaded
+	confDirs set[string]
+
+	// missing are directories which may contain configuration files but do not exist, the closest existing parent of
+	// each is watched so they are noticed when created
+	missing set[string]
+}
+
+// run handles watch events until the context is done, calling reload once changes to configuration files settle
+func (w *configWatcher) run(ctx context.Context, reload func()) {
+	defer func() {
+		_ = w.watcher.Close()
+	}()
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				// watches are removed along with the directory, so it is watched again if recreated
+				w.dirs.remove(name)
+			}
+			if event.Has(fsnotify.Create) && w.isMissingDir(name) {
+				w.cfg.Logger.Debugf("configuration directory created: %s", event)
+				// watch the new directories, files may have been created in them before they were watched
+				if w.update() {
+					timer.Reset(watchDebounce)
+				}
+				continue
+			}
+			if !w.files.contains(name) && !w.isFragment(name) {
+				continue
+			}
+			w.cfg.Logger.Debugf("configuration file changed: %s", event)
+			timer.Reset(watchDebounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.cfg.Logger.Warnf("error watching configuration files: %v", err)
+		case <-timer.C:
+			w.update()
+			reload()
+		}
+	}
+}
+
+// isMissingDir returns true if the name is a directory which is, or is a parent of, a missing configuration directory
+func (w *configWatcher) isMissingDir(name string) bool {
+	if !isDir(name) {
+		return false
+	}
+	for dir := range w.missing {
+		if dir == name || strings.HasPrefix(dir, name+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFragment returns true if the name is a file in a conf.d directory with a supported extension
+func (w *configWatcher) isFragment(name string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	return w.confDirs.contains(filepath.Dir(name)) && slices.Contains(viper.SupportedExts, ext)
+}
+
+// update watches the directories of all configuration files that are loaded, included or may be found, including
+// directories which have been created since the last update; for directories which do not exist, the closest existing
+// parent directory is watched. Returns true if any configuration files exist in newly watched directories
+func (w *configWatcher) update() (found bool) {
+	w.files = set[string]{}
+	w.confDirs = set[string]{}
+	w.missing = set[string]{}
+
+	var locations []string
+	if files, err := findConfigurationFiles(w.cfg); err == nil {
+		// read the files to find included files, which are also watched
+		r := newConfigReader(w.cfg, Provenance{})
+		for _, f := range files {
+			if err := r.readFile(f, nil); err != nil {
+				w.cfg.Logger.Debugf("unable to read configuration file %s: %v", f, err)
+			}
+		}
+		locations = append(locations, r.files...)
+	}
+	locations = append(locations, w.cfg.Files...)
+	if len(Flatten(w.cfg.Files...)) == 0 {
+		locations = append(locations, SummarizeLocations(w.cfg)...)
+
+		// conf.d directories are watched as directories, as fragments may have any name
+		if indexOfFinder(w.cfg.Finders, FindInConfD) >= 0 {
+			for _, dir := range confDDirs(w.cfg) {
+				dir = filepath.Clean(dir)
+				w.confDirs.add(dir)
+				if w.watch(dir) && len(findConfigFragments(osFS{}, dir)) > 0 {
+					found = true
+				}
+			}
+		}
+	}
+
+	for _, location := range locations {
+		if location == "" {
+			continue
+		}
+		file, err := expandHome(w.cfg, location)
+		if err != nil {
+			continue
+		}
+		file = filepath.Clean(workDirPath(w.cfg, file))
+		w.files.add(file)
+
+		if w.watch(filepath.Dir(file)) && fileExists(file) {
+			found = true
+		}
+	}
+	return found
+}
+
+// watch watches the directory, or its closest existing parent if it does not exist, returning true if the directory
+// was not already watched
+func (w *configWatcher) watch(dir string) bool {
+	for !isDir(dir) {
+		w.missing.add(dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+	if w.dirs.contains(dir) {
+		return false
+	}
+	if err := w.watcher.Add(dir); err != nil {
+		w.cfg.Logger.Debugf("unable to watch configuration directory %s: %v", dir, err)
+		return false
+	}
+	w.dirs.add(dir)
+	return true
+}
+
+func isDir(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,221 @@
+package fangs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Watch(t *testing.T) {
+	debounce := watchDebounce
+	watchDebounce = 10 * time.Millisecond
+	t.Cleanup(func() {
+		watchDebounce = debounce
+	})
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	type config struct {
+		Name  string   `mapstructure:"name" validate:"max=10"`
+		Depth int      `mapstructure:"depth"`
+		Items []string `mapstructure:"items"`
+	}
+
+	c := &config{Name: "default", Depth: 1}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().IntVar(&c.Depth, "depth", c.Depth, "")
+	require.NoError(t, cmd.Flags().Parse([]string{"--depth", "2"}))
+
+	cfg := NewConfig("app")
+	cfg.Finders = []Finder{FindInCwd, FindInAppNameSubdir}
+
+	type change struct {
+		old, new *config
+	}
+	changes := make(chan change, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, os.WriteFile(".app.yaml", []byte("name: first\nitems: [a]\n"), 0600))
+
+	err := Watch(ctx, cfg, cmd, c, func(old, new *config) {
+		changes <- change{old, new}
+	})
+	require.NoError(t, err)
+	require.Equal(t, &config{Name: "first", Depth: 2, Items: []string{"a"}}, c)
+
+	next := func() change {
+		t.Helper()
+		select {
+		case ch := <-changes:
+			return ch
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for configuration change")
+		}
+		return change{}
+	}
+
+	// a change to the loaded file, flags still apply and lists are not appended to previous values
+	require.NoError(t, os.WriteFile(".app.yaml", []byte("name: second\nitems: [b]\n"), 0600))
+	ch := next()
+	require.Same(t, c, ch.old)
+	require.Equal(t, &config{Name: "second", Depth: 2, Items: []string{"b"}}, ch.new)
+
+	// invalid configuration is not reported, the next valid change is reported relative to the last valid one
+	require.NoError(t, os.WriteFile(".app.yaml", []byte("name: name-is-too-long\nitems: [c]\n"), 0600))
+	select {
+	case ch := <-changes:
+		t.Fatalf("unexpected change for invalid configuration: %+v", ch.new)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// removing the loaded file reverts to defaults, a directory created before the reload is watched
+	require.NoError(t, os.Mkdir(".app", 0700))
+	require.NoError(t, os.Remove(".app.yaml"))
+	last := next()
+	require.Equal(t, &config{Name: "default", Depth: 2}, last.new)
+
+	// a file created in a searched location
+	require.NoError(t, os.WriteFile(filepath.Join(".app", "config.yaml"), []byte("name: fourth\n"), 0600))
+	ch = next()
+	require.Same(t, last.new, ch.old)
+	require.Equal(t, &config{Name: "fourth", Depth: 2}, ch.new)
+
+	// no further changes after the context is done
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(".app", "config.yaml"), []byte("name: fifth\n"), 0600))
+	select {
+	case ch := <-changes:
+		t.Fatalf("unexpected change after context is done: %+v", ch.new)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func Test_WatchMissingDirectories(t *testing.T) {
+	debounce := watchDebounce
+	watchDebounce = 10 * time.Millisecond
+	t.Cleanup(func() {
+		watchDebounce = debounce
+	})
+
+	home := t.TempDir()
+	t.Chdir(home)
+
+	type config struct {
+		Name string `mapstructure:"name"`
+	}
+
+	cfg := NewConfig("app")
+	cfg.Finders = []Finder{FindInXDG}
+	cfg.LookupEnv = func(key string) (string, bool) {
+		if key == "HOME" {
+			return home, true
+		}
+		return "", false
+	}
+
+	changes := make(chan *config, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &config{Name: "default"}
+	err := Watch(ctx, cfg, nil, c, func(_, new *config) {
+		changes <- new
+	})
+	require.NoError(t, err)
+	require.Equal(t, &config{Name: "default"}, c)
+
+	// neither ~/.config nor ~/.config/app exist when watching starts
+	dir := filepath.Join(home, ".config", "app")
+	require.NoError(t, os.MkdirAll(dir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("name: xdg\n"), 0600))
+
+	select {
+	case ch := <-changes:
+		require.Equal(t, &config{Name: "xdg"}, ch)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for configuration change")
+	}
+}
+
+func Test_WatchConfD(t *testing.T) {
+	debounce := watchDebounce
+	watchDebounce = 10 * time.Millisecond
+	t.Cleanup(func() {
+		watchDebounce = debounce
+	})
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	type config struct {
+		Name  string `mapstructure:"name"`
+		Depth int    `mapstructure:"depth"`
+	}
+
+	cfg := NewConfig("app")
+	cfg.Finders = []Finder{FindInConfD}
+	cfg.LookupEnv = func(key string) (string, bool) {
+		if key == "HOME" {
+			return dir, true
+		}
+		return "", false
+	}
+
+	changes := make(chan *config, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	confD := filepath.Join(".app", "conf.d")
+	require.NoError(t, os.MkdirAll(confD, 0750))
+
+	c := &config{Name: "default"}
+	err := Watch(ctx, cfg, nil, c, func(_, new *config) {
+		changes <- new
+	})
+	require.NoError(t, err)
+
+	next := func() *config {
+		t.Helper()
+		select {
+		case ch := <-changes:
+			return ch
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for configuration change")
+		}
+		return nil
+	}
+
+	// fragments created in an empty conf.d directory
+	require.NoError(t, os.WriteFile(filepath.Join(confD, "10-name.yaml"), []byte("name: fragment\n"), 0600))
+	require.Equal(t, &config{Name: "fragment"}, next())
+
+	require.NoError(t, os.WriteFile(filepath.Join(confD, "20-depth.yml"), []byte("depth: 2\n"), 0600))
+	require.Equal(t, &config{Name: "fragment", Depth: 2}, next())
+
+	// files without a supported extension are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(confD, "README.md"), []byte("name: ignored\n"), 0600))
+	select {
+	case ch := <-changes:
+		t.Fatalf("unexpected change for unsupported file: %+v", ch)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// fragments in a conf.d directory which did not exist when watching started, with a lower precedence than the
+	// fragments in the working directory
+	homeConfD := filepath.Join(dir, ".config", "app", "conf.d")
+	require.NoError(t, os.MkdirAll(homeConfD, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(homeConfD, "10-depth.yaml"), []byte("depth: 3\n"), 0600))
+	require.Equal(t, &config{Name: "fragment", Depth: 2}, next())
+}